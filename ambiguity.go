@@ -0,0 +1,243 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// IntentClass - how reliably a message was classified.
+type IntentClass string
+
+const (
+	// IntentConfident - the top intent clearly wins
+	IntentConfident IntentClass = "confident"
+	// IntentAmbiguous - several intents are close to each other
+	IntentAmbiguous IntentClass = "ambiguous"
+	// IntentUnknown - no intent or the top intent has a low confidence
+	IntentUnknown IntentClass = "unknown"
+)
+
+// AmbiguityOptions - thresholds used by Interpret.
+type AmbiguityOptions struct {
+	// MinConfidence is the confidence below which the top intent is unknown.
+	MinConfidence float64
+	// Margin is the maximum confidence difference between the top intent
+	// and another candidate for the message to be ambiguous.
+	Margin float64
+	// MaxOptions is the maximum number of clarification options.
+	MaxOptions int
+}
+
+// DefaultAmbiguityOptions - used by Interpret when no options are given.
+var DefaultAmbiguityOptions = AmbiguityOptions{
+	MinConfidence: 0.5,
+	Margin:        0.15,
+	MaxOptions:    2,
+}
+
+// Interpretation - result of Interpret.
+type Interpretation struct {
+	Class IntentClass
+	// Intent is the top intent, unset if the message has no intent.
+	Intent MessageIntent
+	// Options are the candidates to clarify, by decreasing confidence.
+	// It's only set for ambiguous messages.
+	Options []MessageIntent
+	Message *MessageResponse
+}
+
+// Interpret - classifies msg as confident, ambiguous or unknown using its
+// N-best intents (see MessageRequest.N).
+func Interpret(msg *MessageResponse, opts *AmbiguityOptions) *Interpretation {
+	if opts == nil {
+		opts = &DefaultAmbiguityOptions
+	}
+
+	interpretation := &Interpretation{Class: IntentUnknown, Message: msg}
+	if msg == nil || len(msg.Intents) == 0 {
+		return interpretation
+	}
+
+	candidates := make([]MessageIntent, len(msg.Intents))
+	copy(candidates, msg.Intents)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Confidence > candidates[j].Confidence
+	})
+
+	top := candidates[0]
+	interpretation.Intent = top
+	if top.Confidence < opts.MinConfidence {
+		return interpretation
+	}
+
+	maxOptions := opts.MaxOptions
+	if maxOptions < 2 {
+		maxOptions = 2
+	}
+
+	options := []MessageIntent{top}
+	for _, c := range candidates[1:] {
+		if len(options) == maxOptions || top.Confidence-c.Confidence > opts.Margin {
+			break
+		}
+		options = append(options, c)
+	}
+
+	if len(options) == 1 {
+		interpretation.Class = IntentConfident
+		return interpretation
+	}
+
+	interpretation.Class = IntentAmbiguous
+	interpretation.Options = options
+	return interpretation
+}
+
+// Question - returns a clarification question such as "Did you mean X or Y?".
+// labels maps intent names to user facing labels; names without label are
+// used as is.
+func (i *Interpretation) Question(labels map[string]string) string {
+	if len(i.Options) == 0 {
+		return ""
+	}
+
+	names := make([]string, len(i.Options))
+	for n, o := range i.Options {
+		names[n] = o.Name
+		if label, ok := labels[o.Name]; ok {
+			names[n] = label
+		}
+	}
+
+	if len(names) == 1 {
+		return fmt.Sprintf("Did you mean %s?", names[0])
+	}
+	return fmt.Sprintf("Did you mean %s or %s?", strings.Join(names[:len(names)-1], ", "), names[len(names)-1])
+}
+
+// Choose - resolves the ambiguity with the intent picked by the user and
+// returns the corresponding training sample.
+func (i *Interpretation) Choose(intent string) (*Training, error) {
+	for _, o := range i.Options {
+		if o.Name == intent {
+			return trainingFromMessage(i.Message, intent), nil
+		}
+	}
+
+	return nil, fmt.Errorf("intent %q is not a clarification option", intent)
+}
+
+// ClarificationLog - collects resolved ambiguities to submit them later as
+// training data. It's safe for concurrent use.
+type ClarificationLog struct {
+	mu        sync.Mutex
+	trainings []Training
+}
+
+// Record - adds a training sample, usually returned by Interpretation.Choose.
+func (l *ClarificationLog) Record(t *Training) {
+	if t == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.trainings = append(l.trainings, *t)
+}
+
+// Trainings - returns the recorded samples.
+func (l *ClarificationLog) Trainings() []Training {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	trainings := make([]Training, len(l.trainings))
+	copy(trainings, l.trainings)
+	return trainings
+}
+
+// Submit - sends the recorded samples with TrainUtterances. They're removed
+// from the log once accepted.
+func (l *ClarificationLog) Submit(c *Client) (*TrainingResponse, error) {
+	trainings := l.Trainings()
+	if len(trainings) == 0 {
+		return nil, errors.New("no trainings to submit")
+	}
+
+	resp, err := c.TrainUtterances(trainings)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	l.trainings = l.trainings[len(trainings):]
+	l.mu.Unlock()
+
+	return resp, nil
+}
+
+// trainingFromMessage builds a training sample from the entities and the
+// most likely trait values of msg, labelled with intent.
+func trainingFromMessage(msg *MessageResponse, intent string) *Training {
+	t := &Training{
+		Text:     msg.Text,
+		Intent:   intent,
+		Entities: []TrainingEntity{},
+		Traits:   []TrainingTrait{},
+	}
+
+	keys := make([]string, 0, len(msg.Entities))
+	for k := range msg.Entities {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, e := range msg.Entities[k] {
+			t.Entities = append(t.Entities, trainingEntity(msg.Text, e))
+		}
+	}
+
+	names := make([]string, 0, len(msg.Traits))
+	for name := range msg.Traits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := msg.Traits[name]
+		if len(values) == 0 {
+			continue
+		}
+		best := values[0]
+		for _, v := range values[1:] {
+			if v.Confidence > best.Confidence {
+				best = v
+			}
+		}
+		t.Traits = append(t.Traits, TrainingTrait{Trait: name, Value: best.Value})
+	}
+
+	return t
+}
+
+// trainingEntity converts e, taking its body from text so that it always
+// matches the entity offsets.
+func trainingEntity(text string, e MessageEntity) TrainingEntity {
+	te := TrainingEntity{
+		Entity:   e.Name + ":" + e.Role,
+		Start:    e.Start,
+		End:      e.End,
+		Body:     e.Body,
+		Entities: []TrainingEntity{},
+	}
+	if body, err := e.Span().Text(text); err == nil {
+		te.Body = body
+	}
+	for _, sub := range e.Entities {
+		te.Entities = append(te.Entities, trainingEntity(text, sub))
+	}
+	return te
+}
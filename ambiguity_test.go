@@ -0,0 +1,128 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpret(t *testing.T) {
+	tests := []struct {
+		name    string
+		intents []MessageIntent
+		class   IntentClass
+		options []string
+	}{
+		{
+			name:  "no intent",
+			class: IntentUnknown,
+		},
+		{
+			name:    "low confidence",
+			intents: []MessageIntent{{Name: "a", Confidence: 0.3}},
+			class:   IntentUnknown,
+		},
+		{
+			name:    "confident",
+			intents: []MessageIntent{{Name: "a", Confidence: 0.9}, {Name: "b", Confidence: 0.5}},
+			class:   IntentConfident,
+		},
+		{
+			name: "ambiguous",
+			intents: []MessageIntent{
+				{Name: "b", Confidence: 0.6},
+				{Name: "a", Confidence: 0.7},
+				{Name: "c", Confidence: 0.65},
+			},
+			class:   IntentAmbiguous,
+			options: []string{"a", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Interpret(&MessageResponse{Intents: tt.intents}, nil)
+			require.Equal(t, tt.class, i.Class)
+
+			var options []string
+			for _, o := range i.Options {
+				options = append(options, o.Name)
+			}
+			require.Equal(t, tt.options, options)
+		})
+	}
+}
+
+func TestInterpretationQuestion(t *testing.T) {
+	i := Interpret(&MessageResponse{Intents: []MessageIntent{
+		{Name: "order_pizza", Confidence: 0.6},
+		{Name: "order_drink", Confidence: 0.55},
+		{Name: "track_order", Confidence: 0.5},
+	}}, &AmbiguityOptions{MinConfidence: 0.5, Margin: 0.2, MaxOptions: 3})
+
+	require.Equal(t, "Did you mean a pizza, order_drink or track_order?", i.Question(map[string]string{
+		"order_pizza": "a pizza",
+	}))
+}
+
+func TestInterpretationChoose(t *testing.T) {
+	msg := &MessageResponse{
+		Text: "book paris",
+		Intents: []MessageIntent{
+			{Name: "book_flight", Confidence: 0.6},
+			{Name: "book_hotel", Confidence: 0.55},
+		},
+		Entities: map[string][]MessageEntity{
+			"wit$location:location": {{Name: "wit$location", Role: "location", Start: 5, End: 10, Body: "paris"}},
+		},
+		Traits: map[string][]MessageTrait{
+			"wit$sentiment": {{Value: "neutral", Confidence: 0.4}, {Value: "positive", Confidence: 0.6}},
+		},
+	}
+
+	i := Interpret(msg, nil)
+	_, err := i.Choose("other")
+	require.Error(t, err)
+
+	training, err := i.Choose("book_hotel")
+	require.NoError(t, err)
+	require.Equal(t, &Training{
+		Text:   "book paris",
+		Intent: "book_hotel",
+		Entities: []TrainingEntity{
+			{Entity: "wit$location:location", Start: 5, End: 10, Body: "paris", Entities: []TrainingEntity{}},
+		},
+		Traits: []TrainingTrait{{Trait: "wit$sentiment", Value: "positive"}},
+	}, training)
+}
+
+func TestClarificationLogSubmit(t *testing.T) {
+	var got []Training
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/utterances" {
+			t.Errorf("unexpected path %v", req.URL.Path)
+		}
+		json.NewDecoder(req.Body).Decode(&got)
+		w.Write([]byte(`{"sent": true, "n": 1}`))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	log := &ClarificationLog{}
+	_, err := log.Submit(c)
+	require.Error(t, err)
+
+	log.Record(&Training{Text: "hi", Intent: "greet"})
+	resp, err := log.Submit(c)
+	require.NoError(t, err)
+	require.Equal(t, &TrainingResponse{Sent: true, N: 1}, resp)
+	require.Equal(t, "greet", got[0].Intent)
+	require.Empty(t, log.Trainings())
+}
@@ -0,0 +1,185 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+// Package audio converts audio into formats accepted by Wit speech endpoints.
+//
+// Audio flows through Source pipelines: decoders (WAV, raw PCM) produce
+// samples, Downmix and Resample transform them and PCMReader encodes them
+// back into bytes for Speech or Dictation requests, without holding whole
+// files in memory:
+//
+//	src, err := audio.NewWAVDecoder(file)
+//	...
+//	pcm, err := audio.NewPCMReader(audio.Resample(audio.Downmix(src), 16000), 16)
+//	...
+//	client.Dictation(witai.DictationRequest{File: pcm, ContentType: pcm.ContentType()})
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Format - sample rate and number of channels of a Source.
+type Format struct {
+	SampleRate int
+	Channels   int
+}
+
+// Source - stream of interleaved samples, as float64 between -1 and 1.
+type Source interface {
+	Format() Format
+	// ReadSamples reads up to len(buf) samples into buf. It only reads
+	// whole frames (one sample per channel) and returns io.EOF at the end
+	// of the stream.
+	ReadSamples(buf []float64) (int, error)
+}
+
+// Encoding - PCM sample encoding.
+type Encoding string
+
+const (
+	// SignedInteger - signed integer samples
+	SignedInteger Encoding = "signed-integer"
+	// UnsignedInteger - unsigned integer samples
+	UnsignedInteger Encoding = "unsigned-integer"
+	// FloatingPoint - IEEE float samples
+	FloatingPoint Encoding = "floating-point"
+)
+
+// MaxChannels - maximum number of channels of PCM audio.
+const MaxChannels = 32
+
+// PCMFormat - layout of raw PCM samples.
+type PCMFormat struct {
+	SampleRate int
+	Channels   int
+	// Bits per sample: 8, 16, 24 or 32 for integers, 32 or 64 for floats.
+	Bits      int
+	Encoding  Encoding
+	BigEndian bool
+}
+
+func (f PCMFormat) validate() error {
+	if f.SampleRate <= 0 || f.Channels <= 0 || f.Channels > MaxChannels {
+		return fmt.Errorf("invalid PCM format: rate %d, channels %d", f.SampleRate, f.Channels)
+	}
+
+	switch f.Encoding {
+	case SignedInteger, UnsignedInteger:
+		if f.Bits != 8 && f.Bits != 16 && f.Bits != 24 && f.Bits != 32 {
+			return fmt.Errorf("invalid PCM format: %d bits integer samples", f.Bits)
+		}
+	case FloatingPoint:
+		if f.Bits != 32 && f.Bits != 64 {
+			return fmt.Errorf("invalid PCM format: %d bits float samples", f.Bits)
+		}
+	default:
+		return fmt.Errorf("invalid PCM format: unknown encoding %q", f.Encoding)
+	}
+
+	return nil
+}
+
+func (f PCMFormat) frameSize() int {
+	return f.Channels * f.Bits / 8
+}
+
+func (f PCMFormat) byteOrder() binary.ByteOrder {
+	if f.BigEndian {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+// pcmDecoder - Source decoding PCM samples from a reader.
+type pcmDecoder struct {
+	r   io.Reader
+	f   PCMFormat
+	buf []byte
+	err error
+}
+
+// NewRawDecoder returns a Source decoding headerless PCM samples from r.
+func NewRawDecoder(r io.Reader, f PCMFormat) (Source, error) {
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+	return &pcmDecoder{r: r, f: f}, nil
+}
+
+func (d *pcmDecoder) Format() Format {
+	return Format{SampleRate: d.f.SampleRate, Channels: d.f.Channels}
+}
+
+func (d *pcmDecoder) ReadSamples(out []float64) (int, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	frames := len(out) / d.f.Channels
+	if frames == 0 {
+		return 0, errors.New("buffer smaller than a frame")
+	}
+
+	size := frames * d.f.frameSize()
+	if cap(d.buf) < size {
+		d.buf = make([]byte, size)
+	}
+	buf := d.buf[:size]
+
+	n, err := io.ReadFull(d.r, buf)
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		// A trailing partial frame is dropped.
+		d.err = io.EOF
+		n -= n % d.f.frameSize()
+	default:
+		d.err = err
+		return 0, err
+	}
+
+	width := d.f.Bits / 8
+	order := d.f.byteOrder()
+	samples := n / width
+	for i := 0; i < samples; i++ {
+		out[i] = decodeSample(buf[i*width:(i+1)*width], d.f, order)
+	}
+
+	if samples == 0 {
+		return 0, d.err
+	}
+	return samples, nil
+}
+
+func decodeSample(b []byte, f PCMFormat, order binary.ByteOrder) float64 {
+	switch f.Encoding {
+	case FloatingPoint:
+		if f.Bits == 32 {
+			return float64(math.Float32frombits(order.Uint32(b)))
+		}
+		return math.Float64frombits(order.Uint64(b))
+	case UnsignedInteger:
+		return float64(int64(readUint(b, order))-int64(1)<<(f.Bits-1)) / float64(int64(1)<<(f.Bits-1))
+	default:
+		v := int64(readUint(b, order)) << (64 - f.Bits) >> (64 - f.Bits)
+		return float64(v) / float64(int64(1)<<(f.Bits-1))
+	}
+}
+
+func readUint(b []byte, order binary.ByteOrder) uint64 {
+	var v uint64
+	if order == binary.BigEndian {
+		for _, c := range b {
+			v = v<<8 | uint64(c)
+		}
+		return v
+	}
+	for i := len(b) - 1; i >= 0; i-- {
+		v = v<<8 | uint64(b[i])
+	}
+	return v
+}
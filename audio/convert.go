@@ -0,0 +1,158 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package audio
+
+import (
+	"errors"
+	"io"
+)
+
+// Downmix returns a mono Source averaging the channels of src.
+func Downmix(src Source) Source {
+	if src.Format().Channels == 1 {
+		return src
+	}
+	return &downmixer{src: src}
+}
+
+type downmixer struct {
+	src Source
+	buf []float64
+}
+
+func (d *downmixer) Format() Format {
+	f := d.src.Format()
+	f.Channels = 1
+	return f
+}
+
+func (d *downmixer) ReadSamples(out []float64) (int, error) {
+	channels := d.src.Format().Channels
+	if cap(d.buf) < len(out)*channels {
+		d.buf = make([]float64, len(out)*channels)
+	}
+
+	n, err := d.src.ReadSamples(d.buf[:len(out)*channels])
+	frames := n / channels
+	for i := 0; i < frames; i++ {
+		var sum float64
+		for _, v := range d.buf[i*channels : (i+1)*channels] {
+			sum += v
+		}
+		out[i] = sum / float64(channels)
+	}
+	return frames, err
+}
+
+// Resample returns a Source converting src to the given sample rate, using
+// linear interpolation. This is meant for speech: no low-pass filter is
+// applied, so frequencies above half of rate may alias when downsampling.
+// src is returned as is if either rate isn't positive.
+func Resample(src Source, rate int) Source {
+	f := src.Format()
+	if f.SampleRate == rate || f.SampleRate <= 0 || rate <= 0 {
+		return src
+	}
+	return &resampler{
+		src:   src,
+		rate:  rate,
+		ratio: float64(f.SampleRate) / float64(rate),
+		prev:  make([]float64, f.Channels),
+		next:  make([]float64, f.Channels),
+		in:    make([]float64, 1024*f.Channels),
+	}
+}
+
+type resampler struct {
+	src   Source
+	rate  int
+	ratio float64
+
+	// The output position is t between the prev and next source frames.
+	prev, next []float64
+	t          float64
+
+	in         []float64
+	inPos, inN int
+	started    bool
+	last       bool
+	done       bool
+	err        error
+}
+
+func (r *resampler) Format() Format {
+	f := r.src.Format()
+	f.SampleRate = r.rate
+	return f
+}
+
+// readFrame reads the next source frame into dst.
+func (r *resampler) readFrame(dst []float64) error {
+	for r.inPos >= r.inN {
+		n, err := r.src.ReadSamples(r.in)
+		r.inPos, r.inN = 0, n
+		if n == 0 && err != nil {
+			return err
+		}
+	}
+	copy(dst, r.in[r.inPos:r.inPos+len(dst)])
+	r.inPos += len(dst)
+	return nil
+}
+
+// advance reads the next source frame, prev being the last one at the end
+// of the source.
+func (r *resampler) advance() {
+	if err := r.readFrame(r.next); err != nil {
+		copy(r.next, r.prev)
+		r.last, r.err = true, err
+	}
+}
+
+func (r *resampler) ReadSamples(out []float64) (int, error) {
+	channels := len(r.prev)
+	if len(out) < channels {
+		return 0, errors.New("buffer smaller than a frame")
+	}
+
+	if !r.started {
+		r.started = true
+		if err := r.readFrame(r.prev); err != nil {
+			r.done, r.err = true, err
+		} else {
+			r.advance()
+		}
+	}
+
+	n := 0
+	for !r.done && n+channels <= len(out) {
+		// Past the last frame, only a position right on it is output.
+		if r.last && r.t > 0 {
+			r.done = true
+			break
+		}
+		for c := 0; c < channels; c++ {
+			out[n+c] = r.prev[c] + (r.next[c]-r.prev[c])*r.t
+		}
+		n += channels
+
+		r.t += r.ratio
+		for r.t >= 1 && !r.done {
+			r.t--
+			if r.last {
+				r.done = true
+				break
+			}
+			r.prev, r.next = r.next, r.prev
+			r.advance()
+		}
+	}
+
+	if n == 0 {
+		if r.err == nil {
+			r.err = io.EOF
+		}
+		return 0, r.err
+	}
+	return n, nil
+}
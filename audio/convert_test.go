@@ -0,0 +1,101 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sliceSource - Source reading from a slice.
+type sliceSource struct {
+	f       Format
+	samples []float64
+}
+
+func (s *sliceSource) Format() Format {
+	return s.f
+}
+
+func (s *sliceSource) ReadSamples(buf []float64) (int, error) {
+	if len(s.samples) == 0 {
+		return 0, io.EOF
+	}
+	n := len(buf) - len(buf)%s.f.Channels
+	n = copy(buf[:n], s.samples)
+	s.samples = s.samples[n:]
+	return n, nil
+}
+
+func TestDownmix(t *testing.T) {
+	src := &sliceSource{f: Format{SampleRate: 8000, Channels: 2}, samples: []float64{1, 0, -0.5, -0.5, 0.2, 0.4}}
+	mono := Downmix(src)
+	require.Equal(t, Format{SampleRate: 8000, Channels: 1}, mono.Format())
+	require.InDeltaSlice(t, []float64{0.5, -0.5, 0.3}, readAll(t, mono), 1e-9)
+
+	same := &sliceSource{f: Format{SampleRate: 8000, Channels: 1}}
+	require.Same(t, same, Downmix(same))
+}
+
+func TestResample(t *testing.T) {
+	up := Resample(&sliceSource{f: Format{SampleRate: 8000, Channels: 1}, samples: []float64{0, 1, 0}}, 16000)
+	require.Equal(t, 16000, up.Format().SampleRate)
+	require.InDeltaSlice(t, []float64{0, 0.5, 1, 0.5, 0}, readAll(t, up), 1e-9)
+
+	down := Resample(&sliceSource{f: Format{SampleRate: 16000, Channels: 2}, samples: []float64{0, 0, 1, -1, 2, -2, 3, -3, 4, -4}}, 8000)
+	require.Equal(t, []float64{0, 0, 2, -2, 4, -4}, readAll(t, down))
+
+	single := Resample(&sliceSource{f: Format{SampleRate: 8000, Channels: 1}, samples: []float64{0.5}}, 16000)
+	require.Equal(t, []float64{0.5}, readAll(t, single))
+
+	empty := Resample(&sliceSource{f: Format{SampleRate: 8000, Channels: 1}}, 16000)
+	require.Empty(t, readAll(t, empty))
+
+	for _, rate := range []int{0, -8000} {
+		src := &sliceSource{f: Format{SampleRate: 8000, Channels: 1}, samples: []float64{0, 1}}
+		require.Same(t, src, Resample(src, rate))
+	}
+}
+
+func TestConvertWAV(t *testing.T) {
+	// Half a second of 440Hz stereo float samples at 44.1kHz.
+	const rate, frames = 44100, 22050
+	data := new(bytes.Buffer)
+	for i := 0; i < frames; i++ {
+		v := float32(0.5 * math.Sin(2*math.Pi*440*float64(i)/rate))
+		binary.Write(data, binary.LittleEndian, []float32{v, v})
+	}
+
+	pcm, err := ConvertWAV(bytes.NewReader(wavFile(wavFormatFloat, 0, 2, rate, 32, data.Bytes())), 0)
+	require.NoError(t, err)
+	require.Equal(t, "audio/raw;encoding=signed-integer;bits=16;rate=16000;endian=little", pcm.ContentType())
+
+	out, err := io.ReadAll(pcm)
+	require.NoError(t, err)
+	require.InDelta(t, 8000*2, len(out), 4)
+
+	var peak int16
+	for i := 0; i+1 < len(out); i += 2 {
+		if v := int16(binary.LittleEndian.Uint16(out[i:])); v > peak {
+			peak = v
+		}
+	}
+	require.InDelta(t, 0.5*math.MaxInt16, float64(peak), 200)
+}
+
+func TestPCMReader(t *testing.T) {
+	_, err := NewPCMReader(&sliceSource{f: Format{SampleRate: 8000, Channels: 2}}, 16)
+	require.Error(t, err)
+
+	pcm, err := NewPCMReader(&sliceSource{f: Format{SampleRate: 8000, Channels: 1}, samples: []float64{1, -1, 2, 0}}, 16)
+	require.NoError(t, err)
+
+	out, err := io.ReadAll(pcm)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xFF, 0x7F, 0x01, 0x80, 0xFF, 0x7F, 0x00, 0x00}, out)
+}
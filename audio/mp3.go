@@ -0,0 +1,299 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DefaultChunkDuration - default maximum length of the audio sent per
+// request, under the audio length limit of Wit.
+const DefaultChunkDuration = 15 * time.Second
+
+// ErrNoMP3Frame is returned when a stream doesn't contain any MPEG audio frame.
+var ErrNoMP3Frame = errors.New("no MP3 frame found")
+
+// Bit rates in kbps, by version (MPEG-1, then MPEG-2 and 2.5) and layer.
+var mp3Bitrates = [2][3][15]int{
+	{
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	},
+	{
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	},
+}
+
+// Sample rates by version bits.
+var mp3SampleRates = map[byte][3]int{
+	3: {44100, 48000, 32000}, // MPEG-1
+	2: {22050, 24000, 16000}, // MPEG-2
+	0: {11025, 12000, 8000},  // MPEG-2.5
+}
+
+// MP3Frame - MPEG audio frame.
+type MP3Frame struct {
+	// Offset is the position of the frame in the stream, in bytes.
+	Offset int64
+	// Size of the frame in bytes, header included.
+	Size int
+	// Bitrate in bits per second.
+	Bitrate    int
+	SampleRate int
+	Channels   int
+	// Samples per channel.
+	Samples int
+}
+
+// Duration - duration of the audio of the frame.
+func (f *MP3Frame) Duration() time.Duration {
+	return time.Duration(f.Samples) * time.Second / time.Duration(f.SampleRate)
+}
+
+// parseMP3Header parses a 4 bytes frame header, returning nil if invalid.
+// Free format frames, whose size can't be known from the header, are not
+// supported.
+func parseMP3Header(h []byte) *MP3Frame {
+	if h[0] != 0xFF || h[1]&0xE0 != 0xE0 {
+		return nil
+	}
+
+	version := h[1] >> 3 & 3
+	layer := 4 - int(h[1]>>1&3) // 1, 2 or 3
+	bitrateIndex := h[2] >> 4
+	rateIndex := h[2] >> 2 & 3
+	padding := int(h[2] >> 1 & 1)
+	if version == 1 || layer == 4 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return nil
+	}
+
+	v := 0
+	if version != 3 {
+		v = 1
+	}
+	f := &MP3Frame{
+		Bitrate:    mp3Bitrates[v][layer-1][bitrateIndex] * 1000,
+		SampleRate: mp3SampleRates[version][rateIndex],
+		Channels:   2,
+	}
+	if h[3]>>6 == 3 {
+		f.Channels = 1
+	}
+
+	switch {
+	case layer == 1:
+		f.Samples = 384
+		f.Size = (12*f.Bitrate/f.SampleRate + padding) * 4
+	case layer == 3 && v == 1:
+		f.Samples = 576
+		f.Size = 72*f.Bitrate/f.SampleRate + padding
+	default:
+		f.Samples = 1152
+		f.Size = 144*f.Bitrate/f.SampleRate + padding
+	}
+
+	return f
+}
+
+// MP3Reader - reads the frames of an MP3 stream.
+//
+// ID3v2 tags and bytes between frames are skipped, reading stops at an
+// ID3v1 tag.
+type MP3Reader struct {
+	r      *bufio.Reader
+	offset int64
+	frames int
+	err    error
+}
+
+// NewMP3Reader returns a reader of the frames of r.
+func NewMP3Reader(r io.Reader) *MP3Reader {
+	return &MP3Reader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next frame and its bytes. It returns io.EOF after the last
+// frame, or ErrNoMP3Frame if the stream has none.
+func (m *MP3Reader) Next() (*MP3Frame, []byte, error) {
+	if m.err != nil {
+		return nil, nil, m.err
+	}
+
+	frame, data, err := m.next()
+	if err != nil {
+		if err == io.EOF && m.frames == 0 {
+			err = ErrNoMP3Frame
+		}
+		m.err = err
+		return nil, nil, err
+	}
+	m.frames++
+	return frame, data, nil
+}
+
+func (m *MP3Reader) next() (*MP3Frame, []byte, error) {
+	for {
+		h, err := m.r.Peek(10)
+		if len(h) < 4 {
+			if err == nil || err == io.EOF || err == bufio.ErrBufferFull {
+				return nil, nil, io.EOF
+			}
+			return nil, nil, err
+		}
+
+		switch {
+		case bytes.HasPrefix(h, []byte("TAG")):
+			return nil, nil, io.EOF
+		case bytes.HasPrefix(h, []byte("ID3")) && len(h) == 10:
+			size := int64(h[6])<<21 | int64(h[7])<<14 | int64(h[8])<<7 | int64(h[9])
+			size += 10
+			if h[5]&0x10 != 0 {
+				size += 10 // footer
+			}
+			if err := m.discard(size); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		frame := parseMP3Header(h)
+		if frame == nil {
+			if err := m.discard(1); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		frame.Offset = m.offset
+		data := make([]byte, frame.Size)
+		n, err := io.ReadFull(m.r, data)
+		m.offset += int64(n)
+		if err == io.ErrUnexpectedEOF {
+			// Truncated last frame.
+			return nil, nil, io.EOF
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return frame, data, nil
+	}
+}
+
+func (m *MP3Reader) discard(n int64) error {
+	skipped, err := io.CopyN(io.Discard, m.r, n)
+	m.offset += skipped
+	if err == io.EOF {
+		return io.EOF
+	}
+	return err
+}
+
+// MP3Info - summary of an MP3 stream.
+type MP3Info struct {
+	Duration time.Duration
+	// Bitrate is the average bit rate in bits per second.
+	Bitrate    int
+	SampleRate int
+	Channels   int
+	Frames     int
+	// Size is the number of bytes of audio frames.
+	Size int64
+}
+
+// ProbeMP3 reads all the frames of r and returns its duration and bit rate.
+func ProbeMP3(r io.Reader) (*MP3Info, error) {
+	m := NewMP3Reader(r)
+	info := &MP3Info{}
+	for {
+		frame, _, err := m.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if info.Frames == 0 {
+			info.SampleRate = frame.SampleRate
+			info.Channels = frame.Channels
+		}
+		info.Frames++
+		info.Size += int64(frame.Size)
+		info.Duration += frame.Duration()
+	}
+
+	if info.Duration > 0 {
+		info.Bitrate = int(float64(info.Size*8) / info.Duration.Seconds())
+	}
+	return info, nil
+}
+
+// MP3Chunk - consecutive frames of an MP3 stream.
+type MP3Chunk struct {
+	// Offset is the start of the chunk in the stream.
+	Offset   time.Duration
+	Duration time.Duration
+	// Data are the frames of the chunk, playable as an MP3 file.
+	Data []byte
+}
+
+// MP3Splitter - splits an MP3 stream on frame boundaries, without
+// re-encoding.
+//
+// Layer III frames may use bytes of the previous frames (bit reservoir): the
+// first few milliseconds of a chunk may not decode exactly as in the
+// original stream.
+type MP3Splitter struct {
+	m       *MP3Reader
+	max     time.Duration
+	offset  time.Duration
+	pending *MP3Chunk
+}
+
+// NewMP3Splitter returns a splitter of r into chunks of at most max, or
+// DefaultChunkDuration if max is 0.
+func NewMP3Splitter(r io.Reader, max time.Duration) *MP3Splitter {
+	if max <= 0 {
+		max = DefaultChunkDuration
+	}
+	return &MP3Splitter{m: NewMP3Reader(r), max: max}
+}
+
+// Next returns the next chunk. It returns io.EOF after the last one.
+func (s *MP3Splitter) Next() (*MP3Chunk, error) {
+	chunk := s.pending
+	s.pending = nil
+	if chunk == nil {
+		chunk = &MP3Chunk{Offset: s.offset}
+	}
+
+	for {
+		frame, data, err := s.m.Next()
+		if err == io.EOF && len(chunk.Data) > 0 {
+			return chunk, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		d := frame.Duration()
+		if d > s.max {
+			return nil, fmt.Errorf("MP3 frame at %d longer than %s", frame.Offset, s.max)
+		}
+		s.offset += d
+
+		if chunk.Duration+d > s.max {
+			s.pending = &MP3Chunk{Offset: s.offset - d, Duration: d, Data: data}
+			return chunk, nil
+		}
+		chunk.Duration += d
+		chunk.Data = append(chunk.Data, data...)
+	}
+}
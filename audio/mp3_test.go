@@ -0,0 +1,117 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package audio
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mp3Frame returns a silent MPEG-1 Layer III frame, 128kbps 44.1kHz mono.
+func mp3Frame() []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0xC0})
+	return frame
+}
+
+func TestParseMP3Header(t *testing.T) {
+	f := parseMP3Header([]byte{0xFF, 0xFB, 0x90, 0xC0})
+	require.NotNil(t, f)
+	require.Equal(t, 128000, f.Bitrate)
+	require.Equal(t, 44100, f.SampleRate)
+	require.Equal(t, 1, f.Channels)
+	require.Equal(t, 417, f.Size)
+	require.Equal(t, 1152, f.Samples)
+
+	// MPEG-2 Layer III, 64kbps 22.05kHz stereo, padded
+	f = parseMP3Header([]byte{0xFF, 0xF3, 0x82, 0x00})
+	require.NotNil(t, f)
+	require.Equal(t, 64000, f.Bitrate)
+	require.Equal(t, 22050, f.SampleRate)
+	require.Equal(t, 2, f.Channels)
+	require.Equal(t, 209, f.Size)
+	require.Equal(t, 576, f.Samples)
+
+	require.Nil(t, parseMP3Header([]byte{0xFF, 0xFB, 0xF0, 0xC0}))
+	require.Nil(t, parseMP3Header([]byte{0xFF, 0xF1, 0x50, 0x80}))
+	require.Nil(t, parseMP3Header([]byte("RIFF")))
+}
+
+func TestProbeMP3(t *testing.T) {
+	f, err := os.Open("../testdata/test.mp3")
+	require.NoError(t, err)
+	defer f.Close()
+
+	info, err := ProbeMP3(f)
+	require.NoError(t, err)
+	require.Equal(t, 161, info.Frames)
+	require.Equal(t, 48000, info.SampleRate)
+	require.Equal(t, 1, info.Channels)
+	require.Equal(t, 3864*time.Millisecond, info.Duration)
+	require.InDelta(t, 116000, info.Bitrate, 1000)
+
+	_, err = ProbeMP3(bytes.NewReader([]byte("not an mp3 file")))
+	require.ErrorIs(t, err, ErrNoMP3Frame)
+}
+
+func TestMP3Reader(t *testing.T) {
+	stream := new(bytes.Buffer)
+	stream.Write([]byte("ID3\x04\x00\x00\x00\x00\x00\x02ab"))
+	stream.Write(mp3Frame())
+	stream.Write([]byte{0x00, 0x01}) // garbage
+	stream.Write(mp3Frame())
+	stream.Write(append([]byte("TAG"), make([]byte, 125)...))
+
+	m := NewMP3Reader(stream)
+	f, data, err := m.Next()
+	require.NoError(t, err)
+	require.EqualValues(t, 12, f.Offset)
+	require.Equal(t, mp3Frame(), data)
+
+	f, _, err = m.Next()
+	require.NoError(t, err)
+	require.EqualValues(t, 12+417+2, f.Offset)
+
+	_, _, err = m.Next()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestMP3Splitter(t *testing.T) {
+	f, err := os.Open("../testdata/test.mp3")
+	require.NoError(t, err)
+	defer f.Close()
+
+	s := NewMP3Splitter(f, time.Second)
+	var (
+		chunks []*MP3Chunk
+		total  time.Duration
+		size   int
+	)
+	for {
+		chunk, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.Equal(t, total, chunk.Offset)
+		require.LessOrEqual(t, chunk.Duration, time.Second)
+
+		// Chunks are valid MP3 streams.
+		info, err := ProbeMP3(bytes.NewReader(chunk.Data))
+		require.NoError(t, err)
+		require.Equal(t, chunk.Duration, info.Duration)
+
+		chunks = append(chunks, chunk)
+		total += chunk.Duration
+		size += len(chunk.Data)
+	}
+
+	require.Len(t, chunks, 4)
+	require.Equal(t, 3864*time.Millisecond, total)
+	require.Equal(t, 56112, size)
+}
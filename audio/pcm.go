@@ -0,0 +1,94 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// DefaultSampleRate - sample rate used by ConvertWAV when none is given.
+const DefaultSampleRate = 16000
+
+// PCMReader - io.Reader encoding a mono Source as little endian signed
+// integer samples.
+type PCMReader struct {
+	src     Source
+	bits    int
+	samples []float64
+	pending []byte
+	err     error
+}
+
+// NewPCMReader returns a reader encoding src with the given number of bits
+// per sample (8, 16 or 32). src must be mono, see Downmix.
+func NewPCMReader(src Source, bits int) (*PCMReader, error) {
+	if bits != 8 && bits != 16 && bits != 32 {
+		return nil, fmt.Errorf("unsupported PCM bits %d", bits)
+	}
+	if channels := src.Format().Channels; channels != 1 {
+		return nil, fmt.Errorf("PCM output must be mono, got %d channels", channels)
+	}
+	return &PCMReader{src: src, bits: bits, samples: make([]float64, 4096)}, nil
+}
+
+// ContentType returns the content type of the encoded samples, to be used
+// as Speech.ContentType or DictationRequest.ContentType.
+func (p *PCMReader) ContentType() string {
+	return fmt.Sprintf("audio/raw;encoding=signed-integer;bits=%d;rate=%d;endian=little", p.bits, p.src.Format().SampleRate)
+}
+
+// Read - reads encoded samples.
+func (p *PCMReader) Read(b []byte) (int, error) {
+	for len(p.pending) == 0 {
+		if p.err != nil {
+			return 0, p.err
+		}
+
+		n, err := p.src.ReadSamples(p.samples)
+		p.err = err
+		p.pending = p.encode(p.samples[:n])
+	}
+
+	n := copy(b, p.pending)
+	p.pending = p.pending[n:]
+	return n, nil
+}
+
+func (p *PCMReader) encode(samples []float64) []byte {
+	width := p.bits / 8
+	out := make([]byte, len(samples)*width)
+	max := float64(int64(1)<<(p.bits-1)) - 1
+
+	for i, v := range samples {
+		v = math.Max(-1, math.Min(1, v))
+		s := int64(math.Round(v * max))
+		b := out[i*width:]
+		switch p.bits {
+		case 8:
+			b[0] = byte(int8(s))
+		case 16:
+			binary.LittleEndian.PutUint16(b, uint16(int16(s)))
+		case 32:
+			binary.LittleEndian.PutUint32(b, uint32(int32(s)))
+		}
+	}
+	return out
+}
+
+// ConvertWAV returns mono 16 bits PCM samples at the given rate (or
+// DefaultSampleRate if 0), decoded from the WAV stream r as it's read.
+func ConvertWAV(r io.Reader, rate int) (*PCMReader, error) {
+	if rate <= 0 {
+		rate = DefaultSampleRate
+	}
+
+	src, err := NewWAVDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPCMReader(Resample(Downmix(src), rate), 16)
+}
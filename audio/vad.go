@@ -0,0 +1,233 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package audio
+
+import (
+	"io"
+	"math"
+	"time"
+)
+
+// VADOptions - options of the voice activity detection, see NewSegmenter.
+// Zero fields use the defaults of DefaultVADOptions.
+type VADOptions struct {
+	// FrameDuration is the analysis window.
+	FrameDuration time.Duration
+	// Threshold is the RMS level, between 0 and 1, above which a frame
+	// contains speech.
+	Threshold float64
+	// MinSilence is the pause ending a segment.
+	MinSilence time.Duration
+	// Padding is the audio kept before and after speech.
+	Padding time.Duration
+	// MaxSegment is the maximum length of a segment, longer speech is
+	// cut into several segments.
+	MaxSegment time.Duration
+}
+
+// DefaultVADOptions - defaults suited to conversations, with segments under
+// the audio length limit of Wit.
+var DefaultVADOptions = VADOptions{
+	FrameDuration: 30 * time.Millisecond,
+	Threshold:     0.01,
+	MinSilence:    500 * time.Millisecond,
+	Padding:       200 * time.Millisecond,
+	MaxSegment:    DefaultChunkDuration,
+}
+
+// Segment - part of a recording containing speech.
+type Segment struct {
+	// Start and End are the position of the segment in the recording.
+	Start, End time.Duration
+	// Samples are the mono samples of the segment.
+	Samples    []float64
+	SampleRate int
+}
+
+// Source returns a Source reading the samples of the segment.
+func (s *Segment) Source() Source {
+	return &segmentSource{f: Format{SampleRate: s.SampleRate, Channels: 1}, samples: s.Samples}
+}
+
+type segmentSource struct {
+	f       Format
+	samples []float64
+}
+
+func (s *segmentSource) Format() Format {
+	return s.f
+}
+
+func (s *segmentSource) ReadSamples(buf []float64) (int, error) {
+	if len(s.samples) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(buf, s.samples)
+	s.samples = s.samples[n:]
+	return n, nil
+}
+
+// Segmenter - splits a recording into speech segments using an energy based
+// voice activity detection. Silence between segments is dropped.
+type Segmenter struct {
+	src  Source
+	opts VADOptions
+
+	frame     []float64
+	frameLen  int
+	position  int // samples read so far
+	padding   [][]float64
+	maxFrames int
+
+	current      []float64
+	currentStart int
+	silence      int // trailing silent samples of current
+	err          error
+}
+
+// NewSegmenter returns a Segmenter reading src, downmixed to mono.
+func NewSegmenter(src Source, opts *VADOptions) *Segmenter {
+	o := DefaultVADOptions
+	if opts != nil {
+		if opts.FrameDuration > 0 {
+			o.FrameDuration = opts.FrameDuration
+		}
+		if opts.Threshold > 0 {
+			o.Threshold = opts.Threshold
+		}
+		if opts.MinSilence > 0 {
+			o.MinSilence = opts.MinSilence
+		}
+		if opts.Padding > 0 {
+			o.Padding = opts.Padding
+		}
+		if opts.MaxSegment > 0 {
+			o.MaxSegment = opts.MaxSegment
+		}
+	}
+
+	src = Downmix(src)
+	rate := src.Format().SampleRate
+	frameLen := durationSamples(o.FrameDuration, rate)
+	if frameLen < 1 {
+		frameLen = 1
+	}
+
+	return &Segmenter{
+		src:       src,
+		opts:      o,
+		frame:     make([]float64, frameLen),
+		frameLen:  frameLen,
+		maxFrames: durationSamples(o.Padding, rate) / frameLen,
+	}
+}
+
+// Next returns the next speech segment. It returns io.EOF after the last one.
+func (s *Segmenter) Next() (*Segment, error) {
+	rate := s.src.Format().SampleRate
+	minSilence := durationSamples(s.opts.MinSilence, rate)
+	maxSegment := durationSamples(s.opts.MaxSegment, rate)
+
+	for s.err == nil {
+		frame, err := s.readFrame()
+		if err != nil {
+			s.err = err
+			break
+		}
+
+		speech := rms(frame) >= s.opts.Threshold
+		start := s.position - len(frame)
+
+		if s.current == nil {
+			if !speech {
+				s.pushPadding(frame)
+				continue
+			}
+			s.currentStart = start
+			for _, p := range s.padding {
+				s.currentStart -= len(p)
+				s.current = append(s.current, p...)
+			}
+			s.padding = s.padding[:0]
+		}
+
+		s.current = append(s.current, frame...)
+		if speech {
+			s.silence = 0
+		} else {
+			s.silence += len(frame)
+		}
+
+		if s.silence >= minSilence || len(s.current) >= maxSegment {
+			return s.flush(rate), nil
+		}
+	}
+
+	if s.current != nil && len(s.current) > s.silence {
+		return s.flush(rate), nil
+	}
+	return nil, s.err
+}
+
+// flush returns the current segment, without its silence beyond padding.
+func (s *Segmenter) flush(rate int) *Segment {
+	samples := s.current
+	if trim := s.silence - durationSamples(s.opts.Padding, rate); trim > 0 {
+		samples = samples[:len(samples)-trim]
+	}
+
+	seg := &Segment{
+		Start:      samplesDuration(s.currentStart, rate),
+		End:        samplesDuration(s.currentStart+len(samples), rate),
+		Samples:    samples,
+		SampleRate: rate,
+	}
+	s.current = nil
+	s.silence = 0
+	return seg
+}
+
+func (s *Segmenter) readFrame() ([]float64, error) {
+	n := 0
+	for n < s.frameLen {
+		m, err := s.src.ReadSamples(s.frame[n:])
+		n += m
+		if err != nil {
+			if n > 0 && err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+	}
+	s.position += n
+
+	frame := make([]float64, n)
+	copy(frame, s.frame[:n])
+	return frame, nil
+}
+
+func (s *Segmenter) pushPadding(frame []float64) {
+	if s.maxFrames == 0 {
+		return
+	}
+	if len(s.padding) == s.maxFrames {
+		s.padding = append(s.padding[:0], s.padding[1:]...)
+	}
+	s.padding = append(s.padding, frame)
+}
+
+func rms(samples []float64) float64 {
+	var sum float64
+	for _, v := range samples {
+		sum += v * v
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+func durationSamples(d time.Duration, rate int) int {
+	return int(d * time.Duration(rate) / time.Second)
+}
+
+func samplesDuration(n int, rate int) time.Duration {
+	return time.Duration(n) * time.Second / time.Duration(rate)
+}
@@ -0,0 +1,75 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package audio
+
+import (
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// speechPattern returns mono samples at 1kHz alternating silence and tones,
+// durations in milliseconds.
+func speechPattern(durations ...int) []float64 {
+	var samples []float64
+	for i, d := range durations {
+		for j := 0; j < d; j++ {
+			v := 0.0
+			if i%2 == 1 {
+				v = 0.5 * math.Sin(2*math.Pi*float64(j)/8)
+			}
+			samples = append(samples, v)
+		}
+	}
+	return samples
+}
+
+func TestSegmenter(t *testing.T) {
+	// silence 1s, speech 2s, silence 1s, speech 1s, silence 0.3s
+	src := &sliceSource{f: Format{SampleRate: 1000, Channels: 1}, samples: speechPattern(1000, 2000, 1000, 1000, 300)}
+	s := NewSegmenter(src, &VADOptions{FrameDuration: 10 * time.Millisecond})
+
+	seg, err := s.Next()
+	require.NoError(t, err)
+	require.Equal(t, 800*time.Millisecond, seg.Start)
+	require.Equal(t, 3200*time.Millisecond, seg.End)
+	require.Len(t, seg.Samples, 2400)
+	require.Len(t, readAll(t, seg.Source()), 2400)
+
+	seg, err = s.Next()
+	require.NoError(t, err)
+	require.Equal(t, 3800*time.Millisecond, seg.Start)
+	require.Equal(t, 5200*time.Millisecond, seg.End)
+
+	_, err = s.Next()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestSegmenterMaxSegment(t *testing.T) {
+	src := &sliceSource{f: Format{SampleRate: 1000, Channels: 2}}
+	for _, v := range speechPattern(0, 2500) {
+		src.samples = append(src.samples, v, v)
+	}
+	s := NewSegmenter(src, &VADOptions{FrameDuration: 10 * time.Millisecond, MaxSegment: time.Second})
+
+	var ends []time.Duration
+	for {
+		seg, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.Equal(t, 1000, seg.SampleRate)
+		ends = append(ends, seg.End)
+	}
+	require.Equal(t, []time.Duration{time.Second, 2 * time.Second, 2500 * time.Millisecond}, ends)
+}
+
+func TestSegmenterSilence(t *testing.T) {
+	src := &sliceSource{f: Format{SampleRate: 1000, Channels: 1}, samples: make([]float64, 3000)}
+	_, err := NewSegmenter(src, nil).Next()
+	require.Equal(t, io.EOF, err)
+}
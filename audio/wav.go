@@ -0,0 +1,134 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+
+	// maxWAVFormatSize bounds the format chunk, 40 bytes in the extensible
+	// layout.
+	maxWAVFormatSize = 64
+)
+
+// WAVDecoder - Source decoding a WAV stream.
+type WAVDecoder struct {
+	pcm PCMFormat
+	Source
+}
+
+// NewWAVDecoder reads the WAV header from r and returns a decoder for its
+// samples. PCM integer (8 to 32 bits) and float (32 or 64 bits) samples are
+// supported.
+func NewWAVDecoder(r io.Reader) (*WAVDecoder, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("unable to read WAV header: %w", err)
+	}
+	if !bytes.Equal(header[:4], []byte("RIFF")) || !bytes.Equal(header[8:], []byte("WAVE")) {
+		return nil, errors.New("not a WAV stream")
+	}
+
+	var (
+		f       PCMFormat
+		hasFmt  bool
+		chunkID [4]byte
+		size    uint32
+	)
+	for {
+		if err := binary.Read(r, binary.LittleEndian, &chunkID); err != nil {
+			return nil, fmt.Errorf("unable to read WAV chunk: %w", err)
+		}
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			return nil, fmt.Errorf("unable to read WAV chunk: %w", err)
+		}
+
+		// Chunks are padded to an even size.
+		padded := int64(size) + int64(size%2)
+
+		switch string(chunkID[:]) {
+		case "fmt ":
+			if size > maxWAVFormatSize {
+				return nil, fmt.Errorf("WAV format chunk too long: %d bytes", size)
+			}
+			chunk := make([]byte, padded)
+			if _, err := io.ReadFull(r, chunk); err != nil {
+				return nil, fmt.Errorf("unable to read WAV format: %w", err)
+			}
+			var err error
+			if f, err = parseWAVFormat(chunk[:size]); err != nil {
+				return nil, err
+			}
+			hasFmt = true
+		case "data":
+			if !hasFmt {
+				return nil, errors.New("WAV data before format")
+			}
+
+			data := r
+			// Streamed WAV files may not know their size.
+			if size != 0 && size != 0xFFFFFFFF {
+				data = io.LimitReader(r, int64(size))
+			}
+
+			src, err := NewRawDecoder(data, f)
+			if err != nil {
+				return nil, err
+			}
+			return &WAVDecoder{pcm: f, Source: src}, nil
+		default:
+			if _, err := io.CopyN(io.Discard, r, padded); err != nil {
+				return nil, fmt.Errorf("unable to skip WAV chunk: %w", err)
+			}
+		}
+	}
+}
+
+// PCMFormat returns the layout of the samples in the file.
+func (d *WAVDecoder) PCMFormat() PCMFormat {
+	return d.pcm
+}
+
+func parseWAVFormat(chunk []byte) (PCMFormat, error) {
+	if len(chunk) < 16 {
+		return PCMFormat{}, errors.New("WAV format chunk too short")
+	}
+
+	tag := binary.LittleEndian.Uint16(chunk[0:2])
+	f := PCMFormat{
+		Channels:   int(binary.LittleEndian.Uint16(chunk[2:4])),
+		SampleRate: int(binary.LittleEndian.Uint32(chunk[4:8])),
+		Bits:       int(binary.LittleEndian.Uint16(chunk[14:16])),
+	}
+
+	// The sub format GUID starts with the actual format tag.
+	if tag == wavFormatExtensible {
+		if len(chunk) < 26 {
+			return PCMFormat{}, errors.New("WAV extensible format chunk too short")
+		}
+		tag = binary.LittleEndian.Uint16(chunk[24:26])
+	}
+
+	switch tag {
+	case wavFormatPCM:
+		f.Encoding = SignedInteger
+		if f.Bits == 8 {
+			f.Encoding = UnsignedInteger
+		}
+	case wavFormatFloat:
+		f.Encoding = FloatingPoint
+	default:
+		return PCMFormat{}, fmt.Errorf("unsupported WAV format 0x%04x", tag)
+	}
+
+	return f, f.validate()
+}
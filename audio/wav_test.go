@@ -0,0 +1,173 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// wavFile builds a WAV file with the given format tag, padding the format
+// chunk to the extensible layout when tag is wavFormatExtensible.
+func wavFile(tag uint16, subTag uint16, channels, rate, bits int, data []byte) []byte {
+	fmtChunk := new(bytes.Buffer)
+	binary.Write(fmtChunk, binary.LittleEndian, tag)
+	binary.Write(fmtChunk, binary.LittleEndian, uint16(channels))
+	binary.Write(fmtChunk, binary.LittleEndian, uint32(rate))
+	binary.Write(fmtChunk, binary.LittleEndian, uint32(rate*channels*bits/8))
+	binary.Write(fmtChunk, binary.LittleEndian, uint16(channels*bits/8))
+	binary.Write(fmtChunk, binary.LittleEndian, uint16(bits))
+	if tag == wavFormatExtensible {
+		binary.Write(fmtChunk, binary.LittleEndian, uint16(22))
+		binary.Write(fmtChunk, binary.LittleEndian, uint16(bits))
+		binary.Write(fmtChunk, binary.LittleEndian, uint32(0))
+		binary.Write(fmtChunk, binary.LittleEndian, subTag)
+		fmtChunk.Write(make([]byte, 14))
+	}
+
+	body := new(bytes.Buffer)
+	body.WriteString("WAVE")
+	// Unknown chunks are skipped.
+	body.WriteString("LIST")
+	binary.Write(body, binary.LittleEndian, uint32(3))
+	body.Write([]byte{1, 2, 3, 0})
+	body.WriteString("fmt ")
+	binary.Write(body, binary.LittleEndian, uint32(fmtChunk.Len()))
+	body.Write(fmtChunk.Bytes())
+	body.WriteString("data")
+	binary.Write(body, binary.LittleEndian, uint32(len(data)))
+	body.Write(data)
+
+	out := new(bytes.Buffer)
+	out.WriteString("RIFF")
+	binary.Write(out, binary.LittleEndian, uint32(body.Len()))
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
+
+func readAll(t *testing.T, src Source) []float64 {
+	t.Helper()
+	var all []float64
+	buf := make([]float64, 4)
+	for {
+		n, err := src.ReadSamples(buf)
+		all = append(all, buf[:n]...)
+		if err == io.EOF {
+			return all
+		}
+		require.NoError(t, err)
+	}
+}
+
+func TestWAVDecoder(t *testing.T) {
+	float32Data := new(bytes.Buffer)
+	binary.Write(float32Data, binary.LittleEndian, []float32{0.5, -0.25, 1, 0})
+	float64Data := new(bytes.Buffer)
+	binary.Write(float64Data, binary.LittleEndian, []float64{0.5, -0.25, 1, 0})
+
+	tests := []struct {
+		name     string
+		file     []byte
+		encoding Encoding
+		want     []float64
+	}{
+		{
+			name:     "8 bits",
+			file:     wavFile(wavFormatPCM, 0, 1, 8000, 8, []byte{128, 0, 192, 64}),
+			encoding: UnsignedInteger,
+			want:     []float64{0, -1, 0.5, -0.5},
+		},
+		{
+			name:     "16 bits",
+			file:     wavFile(wavFormatPCM, 0, 2, 8000, 16, []byte{0x00, 0x40, 0x00, 0x80, 0x00, 0x00, 0x00, 0xC0}),
+			encoding: SignedInteger,
+			want:     []float64{0.5, -1, 0, -0.5},
+		},
+		{
+			name:     "24 bits",
+			file:     wavFile(wavFormatPCM, 0, 1, 8000, 24, []byte{0x00, 0x00, 0x40, 0x00, 0x00, 0xC0}),
+			encoding: SignedInteger,
+			want:     []float64{0.5, -0.5},
+		},
+		{
+			name:     "32 bits float",
+			file:     wavFile(wavFormatFloat, 0, 2, 44100, 32, float32Data.Bytes()),
+			encoding: FloatingPoint,
+			want:     []float64{0.5, -0.25, 1, 0},
+		},
+		{
+			name:     "64 bits float",
+			file:     wavFile(wavFormatFloat, 0, 1, 44100, 64, float64Data.Bytes()),
+			encoding: FloatingPoint,
+			want:     []float64{0.5, -0.25, 1, 0},
+		},
+		{
+			name:     "extensible",
+			file:     wavFile(wavFormatExtensible, wavFormatFloat, 2, 48000, 32, float32Data.Bytes()),
+			encoding: FloatingPoint,
+			want:     []float64{0.5, -0.25, 1, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewWAVDecoder(bytes.NewReader(tt.file))
+			require.NoError(t, err)
+			require.Equal(t, tt.encoding, d.PCMFormat().Encoding)
+			require.InDeltaSlice(t, tt.want, readAll(t, d), 1e-6)
+		})
+	}
+}
+
+func TestWAVDecoderErrors(t *testing.T) {
+	_, err := NewWAVDecoder(bytes.NewReader([]byte("ID3 not a wave file")))
+	require.Error(t, err)
+
+	_, err = NewWAVDecoder(bytes.NewReader(wavFile(6, 0, 1, 8000, 8, []byte{0})))
+	require.ErrorContains(t, err, "unsupported WAV format 0x0006")
+
+	_, err = NewWAVDecoder(bytes.NewReader(wavFile(wavFormatPCM, 0, 65535, 8000, 16, []byte{0})))
+	require.ErrorContains(t, err, "invalid PCM format")
+}
+
+func TestWAVDecoderMalformedChunks(t *testing.T) {
+	chunk := func(id string, size uint32, data []byte) []byte {
+		out := []byte("RIFF\x00\x00\x00\x00WAVE" + id)
+		out = binary.LittleEndian.AppendUint32(out, size)
+		return append(out, data...)
+	}
+
+	for name, file := range map[string][]byte{
+		"truncated chunk header": []byte("RIFF\x00\x00\x00\x00WAVEfmt \x10\x00"),
+		"truncated format":       chunk("fmt ", 16, []byte{1, 0, 1, 0}),
+		"oversized format":       chunk("fmt ", 0xFFFFFFFF, make([]byte, 16)),
+		"large format":           chunk("fmt ", 1<<30, make([]byte, 16)),
+		"truncated chunk":        chunk("LIST", 10, []byte{1, 2, 3}),
+		"oversized chunk":        chunk("LIST", 0xFFFFFFFF, []byte{1, 2, 3}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewWAVDecoder(bytes.NewReader(file))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestRawDecoder(t *testing.T) {
+	_, err := NewRawDecoder(bytes.NewReader(nil), PCMFormat{SampleRate: 8000, Channels: 1, Bits: 12, Encoding: SignedInteger})
+	require.Error(t, err)
+
+	// Trailing partial frames are dropped.
+	src, err := NewRawDecoder(bytes.NewReader([]byte{0x40, 0x00, 0xC0, 0x00, 0x01}), PCMFormat{
+		SampleRate: 8000,
+		Channels:   1,
+		Bits:       16,
+		Encoding:   SignedInteger,
+		BigEndian:  true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []float64{0.5, -0.5}, readAll(t, src))
+}
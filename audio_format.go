@@ -0,0 +1,260 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// AudioContainer - audio MIME type supported by /speech and /dictation.
+type AudioContainer string
+
+const (
+	// AudioWAV - WAV file
+	AudioWAV AudioContainer = "audio/wav"
+	// AudioMP3 - MP3 file
+	AudioMP3 AudioContainer = "audio/mpeg3"
+	// AudioOGG - OGG file
+	AudioOGG AudioContainer = "audio/ogg"
+	// AudioULaw - headerless mu-law samples
+	AudioULaw AudioContainer = "audio/ulaw"
+	// AudioRaw - headerless samples, described by the other AudioFormat fields
+	AudioRaw AudioContainer = "audio/raw"
+)
+
+// AudioEncoding - sample encoding of raw audio.
+type AudioEncoding string
+
+const (
+	// SignedInteger - signed PCM samples
+	SignedInteger AudioEncoding = "signed-integer"
+	// UnsignedInteger - unsigned PCM samples
+	UnsignedInteger AudioEncoding = "unsigned-integer"
+	// FloatingPoint - 32 bits float PCM samples
+	FloatingPoint AudioEncoding = "floating-point"
+	// MuLaw - 8 bits mu-law samples
+	MuLaw AudioEncoding = "mu-law"
+	// ALaw - 8 bits a-law samples
+	ALaw AudioEncoding = "a-law"
+	// IMAADPCM - IMA ADPCM samples
+	IMAADPCM AudioEncoding = "ima-adpcm"
+)
+
+// Endianness - byte order of raw audio samples.
+type Endianness string
+
+const (
+	// LittleEndian - least significant byte first
+	LittleEndian Endianness = "little"
+	// BigEndian - most significant byte first
+	BigEndian Endianness = "big"
+)
+
+// ErrUnknownAudioFormat is returned when a content type isn't supported.
+var ErrUnknownAudioFormat = errors.New("unknown audio format")
+
+var audioContainerAliases = map[string]AudioContainer{
+	"audio/wav":    AudioWAV,
+	"audio/wave":   AudioWAV,
+	"audio/x-wav":  AudioWAV,
+	"audio/mpeg3":  AudioMP3,
+	"audio/mpeg":   AudioMP3,
+	"audio/mp3":    AudioMP3,
+	"audio/ogg":    AudioOGG,
+	"audio/ulaw":   AudioULaw,
+	"audio/raw":    AudioRaw,
+	"audio/x-raw":  AudioRaw,
+	"audio/basic":  AudioULaw,
+	"audio/x-mpeg": AudioMP3,
+}
+
+// AudioFormat - typed representation of the audio content types, e.g.
+// "audio/raw;encoding=unsigned-integer;bits=16;rate=8000;endian=big".
+//
+// Encoding, Bits, Rate and Endian only apply to AudioRaw.
+//
+// https://wit.ai/docs/http/#post__speech_link
+type AudioFormat struct {
+	Container AudioContainer
+	Encoding  AudioEncoding
+	Bits      int
+	Rate      int
+	Endian    Endianness
+}
+
+// ContentType - renders the format as expected by Speech.ContentType and
+// DictationRequest.ContentType.
+func (f AudioFormat) ContentType() string {
+	if f.Container != AudioRaw {
+		return string(f.Container)
+	}
+
+	params := []string{string(f.Container)}
+	if f.Encoding != "" {
+		params = append(params, "encoding="+string(f.Encoding))
+	}
+	if f.Bits != 0 {
+		params = append(params, "bits="+strconv.Itoa(f.Bits))
+	}
+	if f.Rate != 0 {
+		params = append(params, "rate="+strconv.Itoa(f.Rate))
+	}
+	if f.Endian != "" {
+		params = append(params, "endian="+string(f.Endian))
+	}
+	return strings.Join(params, ";")
+}
+
+// String - same as ContentType.
+func (f AudioFormat) String() string {
+	return f.ContentType()
+}
+
+// Validate - checks the format is a combination supported by Wit.
+func (f AudioFormat) Validate() error {
+	switch f.Container {
+	case AudioWAV, AudioMP3, AudioOGG, AudioULaw:
+		return nil
+	case AudioRaw:
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownAudioFormat, f.Container)
+	}
+
+	switch f.Encoding {
+	case SignedInteger, UnsignedInteger, IMAADPCM:
+	case FloatingPoint:
+		if f.Bits != 32 {
+			return fmt.Errorf("invalid audio format %q: floating-point samples must have 32 bits", f)
+		}
+	case MuLaw, ALaw:
+		if f.Bits != 8 {
+			return fmt.Errorf("invalid audio format %q: %s samples must have 8 bits", f, f.Encoding)
+		}
+	case "":
+		return fmt.Errorf("invalid audio format %q: encoding is required", f)
+	default:
+		return fmt.Errorf("invalid audio format %q: unknown encoding %q", f, f.Encoding)
+	}
+
+	if f.Bits != 8 && f.Bits != 16 && f.Bits != 32 {
+		return fmt.Errorf("invalid audio format %q: bits must be 8, 16 or 32", f)
+	}
+	if f.Rate <= 0 {
+		return fmt.Errorf("invalid audio format %q: rate is required", f)
+	}
+
+	switch f.Endian {
+	case LittleEndian, BigEndian:
+	case "":
+		if f.Bits > 8 {
+			return fmt.Errorf("invalid audio format %q: endian is required for %d bits samples", f, f.Bits)
+		}
+	default:
+		return fmt.Errorf("invalid audio format %q: unknown endian %q", f, f.Endian)
+	}
+
+	return nil
+}
+
+// ParseAudioFormat - parses and validates a content type. Common aliases
+// such as "audio/mpeg" and rates such as "16k" are accepted.
+func ParseAudioFormat(contentType string) (AudioFormat, error) {
+	parts := strings.Split(contentType, ";")
+
+	container, ok := audioContainerAliases[strings.ToLower(strings.TrimSpace(parts[0]))]
+	if !ok {
+		return AudioFormat{}, fmt.Errorf("%w: %q", ErrUnknownAudioFormat, contentType)
+	}
+
+	f := AudioFormat{Container: container}
+	for _, p := range parts[1:] {
+		key, value, found := strings.Cut(strings.TrimSpace(p), "=")
+		if !found {
+			return AudioFormat{}, fmt.Errorf("invalid audio format %q: malformed parameter %q", contentType, p)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+
+		var err error
+		switch key {
+		case "encoding":
+			f.Encoding = AudioEncoding(value)
+		case "bits":
+			f.Bits, err = strconv.Atoi(value)
+		case "rate":
+			f.Rate, err = parseRate(value)
+		case "endian":
+			f.Endian = Endianness(value)
+		default:
+			err = fmt.Errorf("unknown parameter %q", key)
+		}
+		if err != nil {
+			return AudioFormat{}, fmt.Errorf("invalid audio format %q: %s", contentType, err.Error())
+		}
+	}
+
+	if err := f.Validate(); err != nil {
+		return AudioFormat{}, err
+	}
+	return f, nil
+}
+
+func parseRate(value string) (int, error) {
+	if strings.HasSuffix(value, "k") {
+		rate, err := strconv.ParseFloat(strings.TrimSuffix(value, "k"), 64)
+		return int(rate * 1000), err
+	}
+	return strconv.Atoi(value)
+}
+
+// SniffAudioFormat - detects the format of r from its first bytes.
+//
+// WAV, MP3 and OGG files are recognized by their header. Audio without a
+// known header is reported as AudioRaw: its encoding can't be detected and
+// must be set by the caller. The returned reader yields the whole audio,
+// sniffed bytes included.
+func SniffAudioFormat(r io.Reader) (AudioFormat, io.Reader, error) {
+	header := make([]byte, 12)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return AudioFormat{}, nil, err
+	}
+	header = header[:n]
+	replay := io.MultiReader(bytes.NewReader(header), r)
+
+	switch {
+	case len(header) >= 12 && bytes.Equal(header[:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WAVE")):
+		return AudioFormat{Container: AudioWAV}, replay, nil
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return AudioFormat{Container: AudioOGG}, replay, nil
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return AudioFormat{Container: AudioMP3}, replay, nil
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0 && header[1]&0x06 != 0:
+		// MPEG audio frame sync, with a layer set (unlike AAC ADTS)
+		return AudioFormat{Container: AudioMP3}, replay, nil
+	}
+
+	return AudioFormat{Container: AudioRaw}, replay, nil
+}
+
+// NewSpeech - returns a Speech for file after validating format.
+func NewSpeech(file io.Reader, format AudioFormat) (*Speech, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	return &Speech{File: file, ContentType: format.ContentType()}, nil
+}
+
+// NewDictationRequest - returns a DictationRequest for file after
+// validating format.
+func NewDictationRequest(file io.Reader, format AudioFormat) (DictationRequest, error) {
+	if err := format.Validate(); err != nil {
+		return DictationRequest{}, err
+	}
+	return DictationRequest{File: file, ContentType: format.ContentType()}, nil
+}
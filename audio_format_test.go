@@ -0,0 +1,109 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudioFormatContentType(t *testing.T) {
+	f := AudioFormat{
+		Container: AudioRaw,
+		Encoding:  UnsignedInteger,
+		Bits:      16,
+		Rate:      8000,
+		Endian:    BigEndian,
+	}
+	require.NoError(t, f.Validate())
+	require.Equal(t, "audio/raw;encoding=unsigned-integer;bits=16;rate=8000;endian=big", f.ContentType())
+
+	require.Equal(t, "audio/mpeg3", AudioFormat{Container: AudioMP3, Rate: 44100}.ContentType())
+}
+
+func TestParseAudioFormat(t *testing.T) {
+	f, err := ParseAudioFormat("audio/raw;encoding=unsigned-integer;bits=16;rate=16k;endian=little")
+	require.NoError(t, err)
+	require.Equal(t, AudioFormat{
+		Container: AudioRaw,
+		Encoding:  UnsignedInteger,
+		Bits:      16,
+		Rate:      16000,
+		Endian:    LittleEndian,
+	}, f)
+
+	f, err = ParseAudioFormat("audio/mpeg")
+	require.NoError(t, err)
+	require.Equal(t, AudioFormat{Container: AudioMP3}, f)
+
+	invalid := []string{
+		"audio/flac",
+		"audio/raw",
+		"audio/raw;encoding=signed-integr;bits=16;rate=8000;endian=little",
+		"audio/raw;encoding=signed-integer;bits=24;rate=8000;endian=little",
+		"audio/raw;encoding=signed-integer;bits=16;rate=8000",
+		"audio/raw;encoding=floating-point;bits=16;rate=8000;endian=little",
+		"audio/raw;encoding=mu-law;bits=16;rate=8000;endian=little",
+		"audio/raw;encoding=signed-integer;bits=16;rate=fast;endian=little",
+		"audio/raw;encoding=signed-integer;bits=16;rate=8000;endian=middle",
+		"audio/raw;encoding",
+	}
+	for _, ct := range invalid {
+		_, err := ParseAudioFormat(ct)
+		require.Error(t, err, ct)
+	}
+
+	_, err = ParseAudioFormat("audio/flac")
+	require.True(t, errors.Is(err, ErrUnknownAudioFormat))
+
+	f, err = ParseAudioFormat("audio/raw;encoding=mu-law;bits=8;rate=8000")
+	require.NoError(t, err)
+	require.Equal(t, MuLaw, f.Encoding)
+}
+
+func TestSniffAudioFormat(t *testing.T) {
+	file, err := os.Open("testdata/test.mp3")
+	require.NoError(t, err)
+	defer file.Close()
+
+	f, r, err := SniffAudioFormat(file)
+	require.NoError(t, err)
+	require.Equal(t, AudioFormat{Container: AudioMP3}, f)
+
+	sniffed, err := io.ReadAll(r)
+	require.NoError(t, err)
+	original, err := os.ReadFile("testdata/test.mp3")
+	require.NoError(t, err)
+	require.Equal(t, original, sniffed)
+
+	tests := []struct {
+		header    []byte
+		container AudioContainer
+	}{
+		{[]byte("RIFF\x24\x08\x00\x00WAVEfmt "), AudioWAV},
+		{[]byte("OggS\x00\x02"), AudioOGG},
+		{[]byte{0xFF, 0xFB, 0x90, 0x64}, AudioMP3},
+		{[]byte{0xFF, 0xF1, 0x50, 0x80}, AudioRaw},
+		{[]byte{0x01, 0x02}, AudioRaw},
+		{nil, AudioRaw},
+	}
+	for _, tt := range tests {
+		f, _, err := SniffAudioFormat(bytes.NewReader(tt.header))
+		require.NoError(t, err)
+		require.Equal(t, tt.container, f.Container)
+	}
+}
+
+func TestNewSpeech(t *testing.T) {
+	s, err := NewSpeech(bytes.NewReader(nil), AudioFormat{Container: AudioWAV})
+	require.NoError(t, err)
+	require.Equal(t, "audio/wav", s.ContentType)
+
+	_, err = NewDictationRequest(bytes.NewReader(nil), AudioFormat{Container: AudioRaw})
+	require.Error(t, err)
+}
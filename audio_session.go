@@ -0,0 +1,182 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrSessionAborted is returned by audio sessions once aborted.
+var ErrSessionAborted = errors.New("audio session aborted")
+
+// audioSession - request whose body is written while it's being sent.
+//
+// The audio is sent with chunked transfer encoding as it's written. Writes
+// block until the previous chunk has been sent, so a slow network slows down
+// the producer instead of buffering audio in memory.
+type audioSession struct {
+	pw     *io.PipeWriter
+	cancel context.CancelFunc
+	done   chan struct{}
+	body   io.ReadCloser
+	err    error
+
+	aborted atomic.Bool
+	once    sync.Once
+	results *jsonStream
+}
+
+func (c *Client) openAudioSession(ctx context.Context, url string, contentType string) *audioSession {
+	ctx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+
+	s := &audioSession{
+		pw:     pw,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	// The exchange lasts as long as the audio, the client timeout would
+	// cut it: it's only bound to ctx.
+	streaming := c.streaming()
+
+	go func() {
+		defer close(s.done)
+		s.body, s.err = streaming.requestContext(ctx, http.MethodPost, url, contentType, pr)
+		if s.err != nil {
+			pr.CloseWithError(s.err)
+		}
+	}()
+
+	return s
+}
+
+// Write - sends an audio chunk.
+func (s *audioSession) Write(p []byte) (int, error) {
+	n, err := s.pw.Write(p)
+	if errors.Is(err, io.ErrClosedPipe) {
+		if s.aborted.Load() {
+			return n, ErrSessionAborted
+		}
+		return n, errors.New("audio session closed")
+	}
+	return n, err
+}
+
+// Close - ends the audio, results can still be read. The response is only
+// released once Next returns an error or io.EOF, see Release.
+func (s *audioSession) Close() error {
+	return s.pw.Close()
+}
+
+// Abort - cancels the request and discards the results.
+func (s *audioSession) Abort() {
+	s.aborted.Store(true)
+	s.pw.CloseWithError(ErrSessionAborted)
+	s.release()
+}
+
+// Release - ends the audio if needed and releases the response, results
+// can't be read anymore. It's safe to defer it right after opening the
+// session, even if the results are read to the end.
+func (s *audioSession) Release() {
+	s.pw.CloseWithError(errors.New("audio session released"))
+	s.release()
+}
+
+func (s *audioSession) release() {
+	s.cancel()
+	<-s.done
+	if s.body != nil {
+		s.body.Close()
+	}
+}
+
+// next decodes the next object of the response into v, waiting for the
+// response to start if needed.
+func (s *audioSession) next(v interface{}) error {
+	s.once.Do(func() {
+		<-s.done
+		if s.err == nil {
+			s.results = newJSONStream(s.body)
+		}
+	})
+	if s.results == nil {
+		return s.err
+	}
+
+	err := s.results.next(v)
+	if err != nil {
+		s.cancel()
+	}
+	return err
+}
+
+// SpeechSession - live /speech request, see OpenSpeechSession.
+type SpeechSession struct {
+	*audioSession
+}
+
+// OpenSpeechSession - starts a /speech request whose audio is written to the
+// returned session as it's captured, e.g. from telephony frames.
+//
+// contentType describes the audio, see Speech.ContentType. The Query, N, Tag,
+// Context and DynamicEntities of req are used if req isn't nil. Events can be
+// read with Next while audio is being written. Close ends the audio, Abort
+// cancels the request. Release must be called unless Next is read until it
+// returns an error or io.EOF.
+func (c *Client) OpenSpeechSession(ctx context.Context, contentType string, req *MessageRequest) (*SpeechSession, error) {
+	if contentType == "" {
+		return nil, errors.New("invalid content type")
+	}
+	if req == nil {
+		req = &MessageRequest{}
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	q := buildParseQuery(req)
+
+	return &SpeechSession{audioSession: c.openAudioSession(ctx, "/speech"+q, contentType)}, nil
+}
+
+// Next - returns the next event. It returns io.EOF after the last event.
+func (s *SpeechSession) Next() (*SpeechEvent, error) {
+	var raw json.RawMessage
+	if err := s.next(&raw); err != nil {
+		return nil, err
+	}
+
+	return decodeSpeechEvent(raw)
+}
+
+// DictationSession - live /dictation request, see OpenDictationSession.
+type DictationSession struct {
+	*audioSession
+}
+
+// OpenDictationSession - starts a /dictation request whose audio is written
+// to the returned session as it's captured. See OpenSpeechSession.
+func (c *Client) OpenDictationSession(ctx context.Context, contentType string) (*DictationSession, error) {
+	if contentType == "" {
+		return nil, errors.New("invalid content type")
+	}
+
+	return &DictationSession{audioSession: c.openAudioSession(ctx, "/dictation", contentType)}, nil
+}
+
+// Next - returns the next transcription. It returns io.EOF after the last one.
+func (s *DictationSession) Next() (*DictationResponse, error) {
+	var resp *DictationResponse
+	if err := s.next(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
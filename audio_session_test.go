@@ -0,0 +1,144 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpeechSession(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if len(req.TransferEncoding) == 0 || req.TransferEncoding[0] != "chunked" {
+			t.Errorf("expected chunked transfer encoding, got %v", req.TransferEncoding)
+		}
+		if q := req.URL.Query().Get("tag"); q != "live" {
+			t.Errorf("unexpected tag %v", q)
+		}
+		http.NewResponseController(w).EnableFullDuplex()
+
+		// Answer with a partial transcription before the audio is complete.
+		first := make([]byte, 4)
+		io.ReadFull(req.Body, first)
+		fmt.Fprintf(w, `{"type": "PARTIAL_TRANSCRIPTION", "text": %q}`, first)
+		w.(http.Flusher).Flush()
+
+		rest, _ := io.ReadAll(req.Body)
+		fmt.Fprintf(w, `{"type": "FINAL_UNDERSTANDING", "text": %q, "intents": []}`, string(first)+string(rest))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	session, err := c.OpenSpeechSession(context.Background(), "audio/raw", &MessageRequest{Tag: "live"})
+	require.NoError(t, err)
+
+	_, err = session.Write([]byte("abcd"))
+	require.NoError(t, err)
+
+	event, err := session.Next()
+	require.NoError(t, err)
+	require.Equal(t, PartialTranscription, event.Type)
+	require.Equal(t, "abcd", event.Text)
+
+	_, err = session.Write([]byte("efgh"))
+	require.NoError(t, err)
+	require.NoError(t, session.Close())
+
+	event, err = session.Next()
+	require.NoError(t, err)
+	require.Equal(t, FinalUnderstanding, event.Type)
+	require.Equal(t, "abcdefgh", event.Text)
+
+	_, err = session.Next()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestDictationSessionAbort(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.Copy(io.Discard, req.Body)
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	session, err := c.OpenDictationSession(context.Background(), "audio/raw")
+	require.NoError(t, err)
+	_, err = session.Write([]byte("abcd"))
+	require.NoError(t, err)
+
+	session.Abort()
+	_, err = session.Write([]byte("efgh"))
+	require.ErrorIs(t, err, ErrSessionAborted)
+
+	_, err = session.Next()
+	require.Error(t, err)
+}
+
+func TestDictationSessionRelease(t *testing.T) {
+	released := make(chan struct{})
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.NewResponseController(w).EnableFullDuplex()
+		w.Write([]byte(`{"type": "PARTIAL_TRANSCRIPTION", "text": "hello"}`))
+		w.(http.Flusher).Flush()
+		io.Copy(io.Discard, req.Body)
+		close(released)
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	session, err := c.OpenDictationSession(context.Background(), "audio/raw")
+	require.NoError(t, err)
+	_, err = session.Write([]byte("abcd"))
+	require.NoError(t, err)
+	resp, err := session.Next()
+	require.NoError(t, err)
+	require.Equal(t, "hello", resp.Text)
+
+	// the response is released without reading it to the end
+	session.Release()
+	<-released
+
+	_, err = session.Write([]byte("efgh"))
+	require.Error(t, err)
+	_, err = session.Next()
+	require.Error(t, err)
+	session.Release()
+}
+
+func TestDictationSessionError(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "invalid content type"}`))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	session, err := c.OpenDictationSession(context.Background(), "audio/nope")
+	require.NoError(t, err)
+	session.Write([]byte("abcd"))
+	session.Close()
+
+	_, err = session.Next()
+	require.EqualError(t, err, "unable to make a request. error: invalid content type")
+}
+
+func TestOpenSessionContentType(t *testing.T) {
+	c := NewClient(unitTestToken)
+	_, err := c.OpenSpeechSession(context.Background(), "", nil)
+	require.EqualError(t, err, "invalid content type")
+	_, err = c.OpenDictationSession(context.Background(), "")
+	require.EqualError(t, err, "invalid content type")
+}
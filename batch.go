@@ -0,0 +1,87 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultBatchConcurrency - number of parallel requests used by batch calls
+// when BatchOptions.Concurrency is not set.
+const DefaultBatchConcurrency = 4
+
+// BatchOptions - options for batch calls such as ParseBatch.
+//
+// Requests are also subject to the client rate limit, see SetRateLimit.
+type BatchOptions struct {
+	// Concurrency is the maximum number of requests in flight.
+	Concurrency int
+	// Progress, if set, is called after each completed item with the
+	// number of completed items. Calls are never concurrent.
+	Progress func(done, total int)
+}
+
+// BatchResult - result of one item of ParseBatch.
+type BatchResult struct {
+	Response *MessageResponse
+	Err      error
+}
+
+// ParseBatch - parses all requests using a bounded pool of workers.
+//
+// Results are returned in the order of reqs. A failing item doesn't stop the
+// batch: its error is reported in BatchResult.Err. The returned error is only
+// set if ctx is done before all items completed.
+func (c *Client) ParseBatch(ctx context.Context, reqs []MessageRequest, opts *BatchOptions) ([]BatchResult, error) {
+	results := make([]BatchResult, len(reqs))
+	runBatch(ctx, len(reqs), opts, func(i int) {
+		results[i].Response, results[i].Err = c.ParseContext(ctx, &reqs[i])
+	})
+
+	return results, ctx.Err()
+}
+
+// runBatch calls fn for every index in [0, n) from a bounded pool of workers.
+func runBatch(ctx context.Context, n int, opts *BatchOptions, fn func(i int)) {
+	concurrency := DefaultBatchConcurrency
+	var progress func(done, total int)
+	if opts != nil {
+		if opts.Concurrency > 0 {
+			concurrency = opts.Concurrency
+		}
+		progress = opts.Progress
+	}
+	if concurrency > n {
+		concurrency = n
+	}
+
+	indexes := make(chan int)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+	)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+
+				if progress != nil {
+					mu.Lock()
+					done++
+					progress(done, n)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
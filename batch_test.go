@@ -0,0 +1,105 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBatch(t *testing.T) {
+	var inFlight, maxInFlight int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		q := req.URL.Query().Get("q")
+		if q == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "bad query"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"text": %q}`, q)
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	reqs := make([]MessageRequest, 20)
+	for i := range reqs {
+		reqs[i].Query = fmt.Sprintf("text %d", i)
+	}
+	reqs[7].Query = "bad"
+
+	var calls, totals []int
+	results, err := c.ParseBatch(context.Background(), reqs, &BatchOptions{
+		Concurrency: 3,
+		Progress: func(done, total int) {
+			calls = append(calls, done)
+			totals = append(totals, total)
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 20)
+	require.Len(t, calls, 20)
+	require.Equal(t, 20, calls[19])
+	require.Equal(t, 20, totals[0])
+	require.LessOrEqual(t, maxInFlight, int32(3))
+
+	for i, r := range results {
+		if i == 7 {
+			require.EqualError(t, r.Err, "unable to make a request. error: bad query")
+			continue
+		}
+		require.NoError(t, r.Err)
+		require.Equal(t, reqs[i].Query, r.Response.Text)
+	}
+}
+
+func TestParseBatchCancel(t *testing.T) {
+	c := NewClient(unitTestToken)
+	c.APIBase = "http://127.0.0.1:0"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := c.ParseBatch(ctx, []MessageRequest{{Query: "a"}, {Query: "b"}}, nil)
+	require.ErrorIs(t, err, context.Canceled)
+	for _, r := range results {
+		require.ErrorIs(t, r.Err, context.Canceled)
+	}
+}
+
+func TestParseBatchRateLimit(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+	c.SetRateLimit(200, 1)
+
+	start := time.Now()
+	results, err := c.ParseBatch(context.Background(), make([]MessageRequest, 5), &BatchOptions{Concurrency: 5})
+	require.NoError(t, err)
+	for _, r := range results {
+		require.NoError(t, r.Err)
+	}
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
@@ -0,0 +1,125 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+// Command wit-tts-prewarm synthesizes prompts ahead of time into a TTS cache
+// directory, see witai.TTSCache.
+//
+// Prompts are read from a file, or stdin, one per line. Lines starting with
+// "<speak" are sent as SSML. Empty lines and lines starting with "#" are
+// skipped.
+//
+//	WIT_AI_TOKEN=... wit-tts-prewarm -dir /var/cache/tts -voice Rebecca prompts.txt
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	witai "github.com/wit-ai/wit-go/v2"
+)
+
+func main() {
+	var (
+		dir         = flag.String("dir", "tts-cache", "cache directory")
+		maxSize     = flag.Int64("max-size", 0, "maximum cache size in bytes, 0 for no limit")
+		voice       = flag.String("voice", "", "voice name (required)")
+		style       = flag.String("style", "", "voice style")
+		speed       = flag.Int("speed", 0, "speed in percent")
+		pitch       = flag.Int("pitch", 0, "pitch in percent")
+		codec       = flag.String("codec", string(witai.CodecMP3), "audio/mpeg, audio/pcm16 or audio/wav")
+		concurrency = flag.Int("concurrency", witai.DefaultBatchConcurrency, "parallel requests")
+		rate        = flag.Float64("rate", 0, "maximum requests per second, 0 for no limit")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [prompts file]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	token := os.Getenv("WIT_AI_TOKEN")
+	if token == "" || *voice == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	input := io.Reader(os.Stdin)
+	if flag.NArg() == 1 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			fail(err)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	template := witai.SynthesizeRequest{
+		Voice: *voice,
+		Style: *style,
+		Speed: *speed,
+		Pitch: *pitch,
+		Codec: witai.SynthesizeCodec(*codec),
+	}
+	reqs, err := readPrompts(input, template)
+	if err != nil {
+		fail(err)
+	}
+
+	client := witai.NewClient(token)
+	client.SetRateLimit(*rate, *concurrency)
+
+	cache, err := witai.NewTTSCache(client, *dir, *maxSize)
+	if err != nil {
+		fail(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err = cache.Prewarm(ctx, reqs, &witai.BatchOptions{
+		Concurrency: *concurrency,
+		Progress: func(done, total int) {
+			fmt.Fprintf(os.Stderr, "\r%d/%d", done, total)
+		},
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		fail(err)
+	}
+
+	fmt.Fprintf(os.Stderr, "%d files, %d bytes in %s\n", cache.Len(), cache.Size(), *dir)
+}
+
+func readPrompts(r io.Reader, template witai.SynthesizeRequest) ([]witai.SynthesizeRequest, error) {
+	var reqs []witai.SynthesizeRequest
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		req := template
+		if strings.HasPrefix(line, "<speak") {
+			req.SSML = line
+		} else {
+			req.Text = line
+		}
+		if err := req.Validate(); err != nil {
+			return nil, fmt.Errorf("line %q: %w", line, err)
+		}
+		reqs = append(reqs, req)
+	}
+
+	return reqs, scanner.Err()
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
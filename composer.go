@@ -0,0 +1,231 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// DefaultComposerMaxSteps - number of /converse calls a ComposerRuntime turn
+// may make before failing, guarding against flows looping on actions.
+const DefaultComposerMaxSteps = 10
+
+// ErrUnknownAction is returned when a flow calls an action without handler.
+var ErrUnknownAction = errors.New("unknown composer action")
+
+// ErrTooManySteps is returned when a flow doesn't wait for user input within
+// the maximum number of steps.
+var ErrTooManySteps = errors.New("too many composer steps")
+
+// EventRequest - client event resuming a Composer flow, e.g. a button click.
+//
+// https://wit.ai/docs/http/#post__event_link
+type EventRequest struct {
+	// SessionID identifies the conversation, it's required.
+	SessionID  string
+	Type       string
+	Message    string
+	ContextMap ContextMap
+}
+
+type eventBody struct {
+	Type       string     `json:"type,omitempty"`
+	Message    string     `json:"message,omitempty"`
+	ContextMap ContextMap `json:"context_map,omitempty"`
+}
+
+// SendEvent - sends a client event to a Composer flow.
+//
+// https://wit.ai/docs/http/#post__event_link
+func (c *Client) SendEvent(ctx context.Context, req *EventRequest) (*ConverseResponse, error) {
+	if req == nil || req.SessionID == "" {
+		return nil, errors.New("invalid event request: session ID is required")
+	}
+
+	body, err := json.Marshal(eventBody{Type: req.Type, Message: req.Message, ContextMap: req.ContextMap})
+	if err != nil {
+		return nil, err
+	}
+
+	q := buildConverseQuery(&ConverseRequest{SessionID: req.SessionID}, false)
+
+	resp, err := c.requestContext(ctx, http.MethodPost, "/event"+q, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return readConverseResponse(resp)
+}
+
+// ActionHandler - runs a client-side Composer action. Changes to contextMap
+// are sent with the next turn.
+type ActionHandler interface {
+	HandleAction(ctx context.Context, contextMap ContextMap) error
+}
+
+// ActionHandlerFunc - adapter to use ordinary functions as ActionHandler.
+type ActionHandlerFunc func(ctx context.Context, contextMap ContextMap) error
+
+// HandleAction calls f(ctx, contextMap).
+func (f ActionHandlerFunc) HandleAction(ctx context.Context, contextMap ContextMap) error {
+	return f(ctx, contextMap)
+}
+
+// ComposerTurn - outcome of a user turn, see ComposerRuntime.
+type ComposerTurn struct {
+	// Responses are the messages for the user, in order.
+	Responses []string
+	// Actions are the executed actions, in order.
+	Actions []string
+	// ContextMap is the context after the turn.
+	ContextMap ContextMap
+	// Last is the response waiting for user input.
+	Last *ConverseResponse
+}
+
+// ComposerRuntime - runs Composer flows, executing their client-side
+// actions until the flow expects user input.
+//
+// The context of each session is kept in a ContextStore. Turns of a same
+// session must not be sent concurrently.
+type ComposerRuntime struct {
+	// MaxSteps is the maximum number of responses per turn,
+	// DefaultComposerMaxSteps if 0.
+	MaxSteps int
+
+	client  *Client
+	store   ContextStore
+	mu      sync.RWMutex
+	actions map[string]ActionHandler
+}
+
+// NewComposerRuntime returns a runtime storing contexts in store, or in
+// memory if store is nil.
+func NewComposerRuntime(client *Client, store ContextStore) *ComposerRuntime {
+	if store == nil {
+		store = NewMemoryContextStore()
+	}
+	return &ComposerRuntime{
+		client:  client,
+		store:   store,
+		actions: make(map[string]ActionHandler),
+	}
+}
+
+// Register sets the handler of a Composer action.
+func (r *ComposerRuntime) Register(action string, h ActionHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.actions[action] = h
+}
+
+// RegisterFunc sets the handler function of a Composer action.
+func (r *ComposerRuntime) RegisterFunc(action string, f func(ctx context.Context, contextMap ContextMap) error) {
+	r.Register(action, ActionHandlerFunc(f))
+}
+
+// Send - sends a user message and runs the flow until it expects input.
+func (r *ComposerRuntime) Send(ctx context.Context, sessionID string, text string) (*ComposerTurn, error) {
+	return r.run(ctx, sessionID, func(contextMap ContextMap) (*ConverseResponse, error) {
+		return r.client.Converse(ctx, &ConverseRequest{SessionID: sessionID, Query: text, ContextMap: contextMap})
+	})
+}
+
+// SendSpeech - same as Send with a spoken message.
+func (r *ComposerRuntime) SendSpeech(ctx context.Context, sessionID string, speech *Speech) (*ComposerTurn, error) {
+	return r.run(ctx, sessionID, func(contextMap ContextMap) (*ConverseResponse, error) {
+		return r.client.ConverseSpeech(ctx, &ConverseRequest{SessionID: sessionID, ContextMap: contextMap}, speech)
+	})
+}
+
+// SendEvent - sends a client event and runs the flow until it expects input.
+// event.SessionID and event.ContextMap are set by the runtime.
+func (r *ComposerRuntime) SendEvent(ctx context.Context, sessionID string, event EventRequest) (*ComposerTurn, error) {
+	return r.run(ctx, sessionID, func(contextMap ContextMap) (*ConverseResponse, error) {
+		event.SessionID = sessionID
+		event.ContextMap = contextMap
+		return r.client.SendEvent(ctx, &event)
+	})
+}
+
+// Reset forgets the context of the session.
+func (r *ComposerRuntime) Reset(sessionID string) error {
+	return r.store.Delete(sessionID)
+}
+
+// run sends the first request of a turn, then resumes the flow after each
+// response not expecting input. The context is saved even if the turn fails,
+// so that actions already executed are not lost.
+func (r *ComposerRuntime) run(ctx context.Context, sessionID string, first func(contextMap ContextMap) (*ConverseResponse, error)) (*ComposerTurn, error) {
+	contextMap, err := r.store.Load(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if contextMap == nil {
+		contextMap = ContextMap{}
+	}
+
+	maxSteps := r.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = DefaultComposerMaxSteps
+	}
+
+	turn := &ComposerTurn{}
+	resp, err := first(contextMap)
+	for step := 1; err == nil; step++ {
+		if resp.ContextMap != nil {
+			contextMap = resp.ContextMap
+		}
+		if text := resp.ResponseText(); text != "" {
+			turn.Responses = append(turn.Responses, text)
+		}
+
+		if resp.Action != "" {
+			if err = r.runAction(ctx, resp.Action, contextMap); err != nil {
+				break
+			}
+			turn.Actions = append(turn.Actions, resp.Action)
+		}
+
+		if resp.ExpectsInput {
+			turn.Last = resp
+			break
+		}
+		if step >= maxSteps {
+			err = fmt.Errorf("%w: %d responses without expecting input", ErrTooManySteps, step)
+			break
+		}
+
+		resp, err = r.client.Converse(ctx, &ConverseRequest{SessionID: sessionID, ContextMap: contextMap})
+	}
+
+	if saveErr := r.store.Save(sessionID, contextMap); err == nil {
+		err = saveErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	turn.ContextMap = contextMap
+	return turn, nil
+}
+
+func (r *ComposerRuntime) runAction(ctx context.Context, action string, contextMap ContextMap) error {
+	r.mu.RLock()
+	h, ok := r.actions[action]
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownAction, action)
+	}
+
+	if err := h.HandleAction(ctx, contextMap); err != nil {
+		return fmt.Errorf("action %q: %w", action, err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendEvent(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/event" || req.URL.Query().Get("session_id") != "s1" {
+			t.Errorf("unexpected request %v", req.URL)
+		}
+
+		var body eventBody
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Errorf("unable to decode body: %v", err)
+		}
+		if body.Type != "message" || body.Message != "yes" || body.ContextMap["a"] != "b" {
+			t.Errorf("unexpected body %v", body)
+		}
+
+		res.Write([]byte(`{"response": {"text": "Done"}, "expects_input": true, "context_map": {"a": "b"}}`))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	resp, err := c.SendEvent(context.Background(), &EventRequest{
+		SessionID:  "s1",
+		Type:       "message",
+		Message:    "yes",
+		ContextMap: ContextMap{"a": "b"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Done", resp.ResponseText())
+
+	_, err = c.SendEvent(context.Background(), &EventRequest{Type: "message"})
+	require.Error(t, err)
+}
+
+// composerServer answers a booking flow: the user message triggers the
+// check_availability action, whose result in the context decides the
+// response.
+func composerServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		var body struct {
+			ContextMap ContextMap `json:"context_map"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Errorf("unable to decode body: %v", err)
+		}
+		if body.ContextMap == nil {
+			body.ContextMap = ContextMap{}
+		}
+		out := map[string]interface{}{"context_map": body.ContextMap}
+
+		switch {
+		case req.URL.Path == "/event":
+			out["response"] = map[string]string{"text": "Cancelled"}
+			out["expects_input"] = true
+		case req.URL.Query().Get("q") == "loop" || body.ContextMap["loop"] == true:
+			body.ContextMap["loop"] = true
+			out["action"] = "noop"
+		case req.URL.Query().Get("q") == "book":
+			body.ContextMap["guests"] = 2
+			out["response"] = map[string]string{"text": "Checking"}
+			out["action"] = "check_availability"
+		case body.ContextMap["available"] == true:
+			out["response"] = map[string]string{"text": "Booked"}
+			out["expects_input"] = true
+		default:
+			out["response"] = map[string]string{"text": "Full"}
+			out["expects_input"] = true
+		}
+
+		json.NewEncoder(res).Encode(out)
+	}))
+}
+
+func TestComposerRuntime(t *testing.T) {
+	testServer := composerServer(t)
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	store, err := NewFileContextStore(t.TempDir())
+	require.NoError(t, err)
+
+	r := NewComposerRuntime(c, store)
+	r.RegisterFunc("check_availability", func(ctx context.Context, contextMap ContextMap) error {
+		contextMap["available"] = contextMap["guests"] == 2.0
+		return nil
+	})
+
+	turn, err := r.Send(context.Background(), "s1", "book")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Checking", "Booked"}, turn.Responses)
+	require.Equal(t, []string{"check_availability"}, turn.Actions)
+	require.True(t, turn.Last.ExpectsInput)
+
+	saved, err := store.Load("s1")
+	require.NoError(t, err)
+	require.Equal(t, ContextMap{"guests": 2.0, "available": true}, saved)
+
+	turn, err = r.SendEvent(context.Background(), "s1", EventRequest{Type: "message", Message: "cancel"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Cancelled"}, turn.Responses)
+	require.Equal(t, ContextMap{"guests": 2.0, "available": true}, turn.ContextMap)
+
+	require.NoError(t, r.Reset("s1"))
+	saved, err = store.Load("s1")
+	require.NoError(t, err)
+	require.Nil(t, saved)
+}
+
+func TestComposerRuntimeErrors(t *testing.T) {
+	testServer := composerServer(t)
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	r := NewComposerRuntime(c, nil)
+	_, err := r.Send(context.Background(), "s1", "book")
+	require.ErrorIs(t, err, ErrUnknownAction)
+
+	// The context is saved up to the failure.
+	saved, err := r.store.Load("s1")
+	require.NoError(t, err)
+	require.Equal(t, ContextMap{"guests": 2.0}, saved)
+
+	failure := errors.New("no database")
+	r.RegisterFunc("check_availability", func(ctx context.Context, contextMap ContextMap) error {
+		return failure
+	})
+	_, err = r.Send(context.Background(), "s1", "book")
+	require.ErrorIs(t, err, failure)
+
+	r.RegisterFunc("noop", func(ctx context.Context, contextMap ContextMap) error {
+		return nil
+	})
+	r.MaxSteps = 3
+	_, err = r.Send(context.Background(), "s2", "loop")
+	require.ErrorIs(t, err, ErrTooManySteps)
+}
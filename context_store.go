@@ -0,0 +1,74 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import "os"
+
+// ContextStore - persists the context map of Composer sessions.
+//
+// Load returns a nil context and nil error when the session is unknown.
+type ContextStore interface {
+	Load(sessionID string) (ContextMap, error)
+	Save(sessionID string, contextMap ContextMap) error
+	Delete(sessionID string) error
+}
+
+// MemoryContextStore - ContextStore keeping contexts in memory.
+type MemoryContextStore struct {
+	store *memoryStore[ContextMap]
+}
+
+// NewMemoryContextStore returns an empty in-memory store.
+func NewMemoryContextStore() *MemoryContextStore {
+	return &MemoryContextStore{store: newMemoryStore(copyContextMap)}
+}
+
+// Load returns a copy of the session context.
+func (s *MemoryContextStore) Load(sessionID string) (ContextMap, error) {
+	return s.store.load(sessionID), nil
+}
+
+// Save stores a copy of the session context.
+func (s *MemoryContextStore) Save(sessionID string, contextMap ContextMap) error {
+	s.store.save(sessionID, contextMap)
+	return nil
+}
+
+// Delete removes the session context.
+func (s *MemoryContextStore) Delete(sessionID string) error {
+	s.store.delete(sessionID)
+	return nil
+}
+
+// FileContextStore - ContextStore keeping one JSON file per session in Dir.
+type FileContextStore struct {
+	Dir string
+}
+
+// NewFileContextStore creates dir if needed and returns a store using it.
+func NewFileContextStore(dir string) (*FileContextStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &FileContextStore{Dir: dir}, nil
+}
+
+// Load reads the session context from disk.
+func (s *FileContextStore) Load(sessionID string) (ContextMap, error) {
+	return fileStore[ContextMap]{dir: s.Dir}.load(sessionID)
+}
+
+// Save atomically writes the session context to disk.
+func (s *FileContextStore) Save(sessionID string, contextMap ContextMap) error {
+	if contextMap == nil {
+		contextMap = ContextMap{}
+	}
+
+	return fileStore[ContextMap]{dir: s.Dir}.save(sessionID, contextMap)
+}
+
+// Delete removes the session file.
+func (s *FileContextStore) Delete(sessionID string) error {
+	return fileStore[ContextMap]{dir: s.Dir}.delete(sessionID)
+}
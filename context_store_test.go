@@ -0,0 +1,27 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testContextStore(t *testing.T, store ContextStore) {
+	testStore[ContextMap](t, store, func() ContextMap {
+		return ContextMap{"name": "ann", "guests": 2.0}
+	}, func(contextMap ContextMap) {
+		contextMap["name"] = "bob"
+	})
+}
+
+func TestMemoryContextStore(t *testing.T) {
+	testContextStore(t, NewMemoryContextStore())
+}
+
+func TestFileContextStore(t *testing.T) {
+	store, err := NewFileContextStore(t.TempDir())
+	require.NoError(t, err)
+	testContextStore(t, store)
+}
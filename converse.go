@@ -0,0 +1,265 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// ContextMap - state of a Composer flow, carried between turns.
+type ContextMap map[string]interface{}
+
+// ConverseRequest - turn of a Composer conversation.
+//
+// https://wit.ai/docs/http/#post__converse_link
+type ConverseRequest struct {
+	// SessionID identifies the conversation, it's required.
+	SessionID string
+	// Query is the user message. It's empty when resuming the flow after
+	// an action.
+	Query      string
+	ContextMap ContextMap
+}
+
+// ConverseResponse - what the app does next.
+type ConverseResponse struct {
+	// Action is the client-side action to execute, if any.
+	Action string `json:"action,omitempty"`
+	// Response is the message for the user, if any.
+	Response *ConverseMessage `json:"response,omitempty"`
+	// ExpectsInput is true when the flow waits for a user message, false
+	// when it should be resumed, e.g. after executing Action.
+	ExpectsInput bool `json:"expects_input"`
+	// ContextMap is the updated context, to send with the next turn.
+	ContextMap ContextMap `json:"context_map,omitempty"`
+	IsFinal    bool       `json:"is_final,omitempty"`
+	// Text is the transcription of the user audio, for ConverseSpeech.
+	Text string `json:"text,omitempty"`
+}
+
+// ConverseMessage - message for the user.
+type ConverseMessage struct {
+	Text string `json:"text"`
+}
+
+// ResponseText - returns the text of the message for the user, if any.
+func (r *ConverseResponse) ResponseText() string {
+	if r.Response == nil {
+		return ""
+	}
+	return r.Response.Text
+}
+
+type converseBody struct {
+	ContextMap ContextMap `json:"context_map,omitempty"`
+}
+
+// Converse - sends a turn of a Composer conversation.
+//
+// https://wit.ai/docs/http/#post__converse_link
+func (c *Client) Converse(ctx context.Context, req *ConverseRequest) (*ConverseResponse, error) {
+	if req == nil || req.SessionID == "" {
+		return nil, errors.New("invalid converse request: session ID is required")
+	}
+	if CharCount(req.Query) > MaxQueryLength {
+		return nil, ErrQueryTooLong
+	}
+
+	body, err := json.Marshal(converseBody{ContextMap: req.ContextMap})
+	if err != nil {
+		return nil, err
+	}
+
+	q := buildConverseQuery(req, false)
+
+	resp, err := c.requestContext(ctx, http.MethodPost, "/converse"+q, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return readConverseResponse(resp)
+}
+
+// ConverseSpeech - same as Converse with the user message spoken in speech.
+// req.Query is ignored.
+func (c *Client) ConverseSpeech(ctx context.Context, req *ConverseRequest, speech *Speech) (*ConverseResponse, error) {
+	if req == nil || req.SessionID == "" {
+		return nil, errors.New("invalid converse request: session ID is required")
+	}
+	if speech == nil {
+		return nil, errors.New("invalid converse request: speech is required")
+	}
+
+	// The body is the audio, the context goes in the query.
+	q := buildConverseQuery(req, true)
+
+	// The request lasts as long as the audio, it's only bound to ctx.
+	resp, err := c.streaming().requestContext(ctx, http.MethodPost, "/converse"+q, speech.ContentType, speech.File)
+	if err != nil {
+		return nil, err
+	}
+
+	return readConverseResponse(resp)
+}
+
+func buildConverseQuery(req *ConverseRequest, speech bool) string {
+	q := "?session_id=" + url.QueryEscape(req.SessionID)
+	if !speech && req.Query != "" {
+		q += "&q=" + url.QueryEscape(req.Query)
+	}
+	if speech && len(req.ContextMap) > 0 {
+		b, _ := json.Marshal(req.ContextMap)
+		if b != nil {
+			q += "&context_map=" + url.QueryEscape(string(b))
+		}
+	}
+	return q
+}
+
+// readConverseResponse returns the final response of the stream, or the last
+// one if none is final. Partial transcriptions come before it.
+func readConverseResponse(body io.ReadCloser) (*ConverseResponse, error) {
+	stream := newJSONStream(body)
+	defer stream.close()
+
+	var last *ConverseResponse
+	for {
+		var resp *ConverseResponse
+		err := stream.next(&resp)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if resp == nil {
+			continue
+		}
+
+		// Keep the transcription of partial responses.
+		if resp.Text == "" && last != nil {
+			resp.Text = last.Text
+		}
+		last = resp
+		if resp.IsFinal {
+			break
+		}
+	}
+
+	if last == nil {
+		return nil, errors.New("no response from converse")
+	}
+	return last, nil
+}
+
+// ConverseSession - Composer conversation keeping its context between turns.
+// It's safe for concurrent use, turns are sent one at a time.
+type ConverseSession struct {
+	ID string
+
+	client  *Client
+	mu      sync.Mutex
+	context ContextMap
+}
+
+// NewConverseSession returns a session starting with contextMap, which may be
+// nil. A random ID is used if id is empty.
+func (c *Client) NewConverseSession(id string, contextMap ContextMap) *ConverseSession {
+	if id == "" {
+		id = newSessionID()
+	}
+	if contextMap == nil {
+		contextMap = ContextMap{}
+	}
+	return &ConverseSession{ID: id, client: c, context: contextMap}
+}
+
+func newSessionID() string {
+	b := make([]byte, 16)
+	// crypto/rand doesn't fail on supported platforms.
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ContextMap - returns a copy of the current context.
+func (s *ConverseSession) ContextMap() ContextMap {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return copyContextMap(s.context)
+}
+
+// SetContextMap - replaces the context sent with the next turn.
+func (s *ConverseSession) SetContextMap(contextMap ContextMap) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.context = copyContextMap(contextMap)
+}
+
+// Send - sends a user message.
+func (s *ConverseSession) Send(ctx context.Context, text string) (*ConverseResponse, error) {
+	return s.turn(func(req *ConverseRequest) (*ConverseResponse, error) {
+		req.Query = text
+		return s.client.Converse(ctx, req)
+	})
+}
+
+// SendSpeech - sends a spoken user message.
+func (s *ConverseSession) SendSpeech(ctx context.Context, speech *Speech) (*ConverseResponse, error) {
+	return s.turn(func(req *ConverseRequest) (*ConverseResponse, error) {
+		return s.client.ConverseSpeech(ctx, req, speech)
+	})
+}
+
+// Continue - resumes the flow without user message, e.g. after executing
+// an action.
+func (s *ConverseSession) Continue(ctx context.Context) (*ConverseResponse, error) {
+	return s.turn(func(req *ConverseRequest) (*ConverseResponse, error) {
+		return s.client.Converse(ctx, req)
+	})
+}
+
+// turn sends a request with the current context and keeps the context of
+// the response.
+func (s *ConverseSession) turn(send func(req *ConverseRequest) (*ConverseResponse, error)) (*ConverseResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp, err := send(&ConverseRequest{SessionID: s.ID, ContextMap: s.context})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.ContextMap != nil {
+		s.context = resp.ContextMap
+	}
+	return resp, nil
+}
+
+// copyContextMap returns a deep copy of m, through JSON as context values
+// are JSON values.
+func copyContextMap(m ContextMap) ContextMap {
+	if m == nil {
+		return ContextMap{}
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return m
+	}
+	var c ContextMap
+	if err := json.Unmarshal(b, &c); err != nil {
+		return m
+	}
+	return c
+}
@@ -0,0 +1,150 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConverse(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/converse" {
+			t.Errorf("unexpected path %v", req.URL.Path)
+		}
+		if id := req.URL.Query().Get("session_id"); id != "s1" {
+			t.Errorf("unexpected session %v", id)
+		}
+		if q := req.URL.Query().Get("q"); q != "book a table" {
+			t.Errorf("unexpected query %v", q)
+		}
+
+		var body converseBody
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.ContextMap["user"] != "ann" {
+			t.Errorf("unexpected body %v %v", body, err)
+		}
+
+		res.Write([]byte(`{
+			"action": "check_availability",
+			"expects_input": false,
+			"context_map": {"user": "ann", "guests": 2},
+			"response": {"text": "Let me check"},
+			"is_final": true
+		}`))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	resp, err := c.Converse(context.Background(), &ConverseRequest{
+		SessionID:  "s1",
+		Query:      "book a table",
+		ContextMap: ContextMap{"user": "ann"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "check_availability", resp.Action)
+	require.Equal(t, "Let me check", resp.ResponseText())
+	require.False(t, resp.ExpectsInput)
+	require.Equal(t, ContextMap{"user": "ann", "guests": 2.0}, resp.ContextMap)
+
+	_, err = c.Converse(context.Background(), &ConverseRequest{Query: "hi"})
+	require.ErrorContains(t, err, "session ID is required")
+}
+
+func TestConverseSpeech(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if ct := req.Header.Get("Content-Type"); ct != "audio/wav" {
+			t.Errorf("unexpected content type %v", ct)
+		}
+		if cm := req.URL.Query().Get("context_map"); cm != `{"user":"ann"}` {
+			t.Errorf("unexpected context map %v", cm)
+		}
+		if b, _ := io.ReadAll(req.Body); string(b) != "audio" {
+			t.Errorf("unexpected body %q", b)
+		}
+
+		res.Write([]byte(`{"text": "book", "is_final": false}
+{"text": "book a table", "is_final": false}
+{"response": {"text": "For how many?"}, "expects_input": true, "context_map": {"user": "ann"}, "is_final": true}`))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	resp, err := c.ConverseSpeech(context.Background(), &ConverseRequest{SessionID: "s1", ContextMap: ContextMap{"user": "ann"}}, &Speech{
+		File:        bytes.NewReader([]byte("audio")),
+		ContentType: "audio/wav",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "book a table", resp.Text)
+	require.Equal(t, "For how many?", resp.ResponseText())
+	require.True(t, resp.ExpectsInput)
+}
+
+func TestConverseSpeechSlowResponse(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		io.Copy(io.Discard, req.Body)
+		time.Sleep(100 * time.Millisecond)
+		res.Write([]byte(`{"text": "book a table", "response": {"text": "For how many?"}, "is_final": true}`))
+	}))
+	defer testServer.Close()
+
+	// the turn outlives the client timeout
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+	c.SetHTTPClient(&http.Client{Timeout: 20 * time.Millisecond})
+
+	resp, err := c.ConverseSpeech(context.Background(), &ConverseRequest{SessionID: "s1"}, &Speech{
+		File:        bytes.NewReader([]byte("audio")),
+		ContentType: "audio/wav",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "For how many?", resp.ResponseText())
+}
+
+func TestConverseSession(t *testing.T) {
+	var contexts []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+		contexts = append(contexts, string(b))
+
+		switch req.URL.Query().Get("q") {
+		case "hi":
+			res.Write([]byte(`{"response": {"text": "Hello"}, "expects_input": true, "context_map": {"step": 1}}`))
+		default:
+			res.Write([]byte(`{"expects_input": true}`))
+		}
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	s := c.NewConverseSession("", ContextMap{"step": 0})
+	require.Len(t, s.ID, 32)
+
+	_, err := s.Send(context.Background(), "hi")
+	require.NoError(t, err)
+	require.Equal(t, ContextMap{"step": 1.0}, s.ContextMap())
+
+	// Responses without context keep the current one.
+	_, err = s.Continue(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, ContextMap{"step": 1.0}, s.ContextMap())
+
+	require.Equal(t, []string{`{"context_map":{"step":0}}`, `{"context_map":{"step":1}}`}, contexts)
+
+	// The returned context is a copy.
+	s.ContextMap()["step"] = 5
+	require.Equal(t, ContextMap{"step": 1.0}, s.ContextMap())
+}
@@ -0,0 +1,269 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DialogStatus - outcome of a dialog turn.
+type DialogStatus string
+
+const (
+	// DialogPrompt - a required slot is missing, ask the user for it
+	DialogPrompt DialogStatus = "prompt"
+	// DialogConfirm - all slots are filled, ask the user to confirm
+	DialogConfirm DialogStatus = "confirm"
+	// DialogComplete - all slots are filled (and confirmed)
+	DialogComplete DialogStatus = "complete"
+	// DialogCancelled - the user cancelled or declined the pending intent
+	DialogCancelled DialogStatus = "cancelled"
+	// DialogNoMatch - the message does not start or continue a dialog
+	DialogNoMatch DialogStatus = "no_match"
+)
+
+// Slot - an entity required by a dialog intent.
+type Slot struct {
+	// Name is the key of the slot in DialogTurn.Slots.
+	Name string
+	// Entity is the entity key (e.g. "wit$datetime:datetime") or
+	// entity name (e.g. "wit$datetime") filling this slot.
+	Entity string
+	// Prompt is asked when the slot is missing. Occurrences of
+	// {slot_name} are replaced by the body of already filled slots.
+	Prompt string
+}
+
+// DialogIntent - declares the slots required by an intent.
+type DialogIntent struct {
+	Name  string
+	Slots []Slot
+	// ConfirmPrompt is asked once all slots are filled. The dialog
+	// completes without confirmation when empty.
+	ConfirmPrompt string
+}
+
+// DialogState - pending intent of a session.
+type DialogState struct {
+	Intent               string                   `json:"intent"`
+	Slots                map[string]MessageEntity `json:"slots"`
+	AwaitingConfirmation bool                     `json:"awaiting_confirmation"`
+	UpdatedAt            time.Time                `json:"updated_at"`
+}
+
+func (s *DialogState) clone() *DialogState {
+	c := *s
+	c.Slots = make(map[string]MessageEntity, len(s.Slots))
+	for k, v := range s.Slots {
+		c.Slots[k] = v
+	}
+	return &c
+}
+
+// DialogTurn - result of processing one user message.
+type DialogTurn struct {
+	Status DialogStatus
+	Intent string
+	Slots  map[string]MessageEntity
+	// Slot is the name of the missing slot when Status is DialogPrompt.
+	Slot string
+	// Prompt is the follow-up question for DialogPrompt and DialogConfirm.
+	Prompt  string
+	Message *MessageResponse
+}
+
+// DialogManager - fills intent slots across several turns.
+type DialogManager struct {
+	// Threshold is the minimum confidence for an intent to start a
+	// dialog or to be taken as a confirmation or cancellation.
+	Threshold float64
+	// ConfirmIntent, DenyIntent and CancelIntent name the intents
+	// answering confirmation prompts and cancelling pending dialogs.
+	ConfirmIntent string
+	DenyIntent    string
+	CancelIntent  string
+
+	client  *Client
+	store   SessionStore
+	mu      sync.RWMutex
+	intents map[string]DialogIntent
+}
+
+// NewDialogManager returns a DialogManager using Wit built-in intents for
+// confirmation and cancellation. A memory store is used when store is nil.
+func NewDialogManager(client *Client, store SessionStore) *DialogManager {
+	if store == nil {
+		store = NewMemorySessionStore()
+	}
+
+	return &DialogManager{
+		ConfirmIntent: "wit$confirmation",
+		DenyIntent:    "wit$negation",
+		CancelIntent:  "wit$cancel",
+		client:        client,
+		store:         store,
+		intents:       make(map[string]DialogIntent),
+	}
+}
+
+// Register declares the slots of an intent.
+func (m *DialogManager) Register(intent DialogIntent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.intents[intent.Name] = intent
+}
+
+// Handle parses text and advances the dialog of the session.
+func (m *DialogManager) Handle(ctx context.Context, sessionID string, text string) (*DialogTurn, error) {
+	if m.client == nil {
+		return nil, errors.New("dialog manager has no client")
+	}
+
+	msg, err := m.client.ParseContext(ctx, &MessageRequest{Query: text})
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Advance(sessionID, msg)
+}
+
+// Advance merges an already parsed message into the dialog of the session.
+func (m *DialogManager) Advance(sessionID string, msg *MessageResponse) (*DialogTurn, error) {
+	if msg == nil {
+		return nil, errors.New("invalid message")
+	}
+
+	state, err := m.store.Load(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	intent, ok := topIntent(msg)
+	confident := ok && intent.Confidence >= m.Threshold
+
+	if state != nil && confident {
+		cancelled := intent.Name == m.CancelIntent ||
+			(state.AwaitingConfirmation && intent.Name == m.DenyIntent)
+		if cancelled {
+			return m.finish(sessionID, state, msg, DialogCancelled)
+		}
+		if state.AwaitingConfirmation && intent.Name == m.ConfirmIntent {
+			return m.finish(sessionID, state, msg, DialogComplete)
+		}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if confident {
+		if _, ok := m.intents[intent.Name]; ok && (state == nil || state.Intent != intent.Name) {
+			state = &DialogState{Intent: intent.Name, Slots: make(map[string]MessageEntity)}
+		}
+	}
+	if state == nil {
+		return &DialogTurn{Status: DialogNoMatch, Message: msg}, nil
+	}
+	if state.Slots == nil {
+		state.Slots = make(map[string]MessageEntity)
+	}
+
+	di, ok := m.intents[state.Intent]
+	if !ok {
+		return m.finish(sessionID, state, msg, DialogNoMatch)
+	}
+
+	for _, slot := range di.Slots {
+		if e, ok := bestEntity(msg, slot.Entity); ok {
+			state.Slots[slot.Name] = e
+		}
+	}
+	state.AwaitingConfirmation = false
+	state.UpdatedAt = time.Now()
+
+	turn := &DialogTurn{Intent: state.Intent, Slots: state.Slots, Message: msg}
+	for _, slot := range di.Slots {
+		if _, ok := state.Slots[slot.Name]; !ok {
+			turn.Status = DialogPrompt
+			turn.Slot = slot.Name
+			turn.Prompt = fillPrompt(slot.Prompt, state.Slots)
+			return turn, m.store.Save(sessionID, state)
+		}
+	}
+
+	if di.ConfirmPrompt == "" {
+		return m.finish(sessionID, state, msg, DialogComplete)
+	}
+
+	state.AwaitingConfirmation = true
+	turn.Status = DialogConfirm
+	turn.Prompt = fillPrompt(di.ConfirmPrompt, state.Slots)
+	return turn, m.store.Save(sessionID, state)
+}
+
+// Reset drops the pending dialog of the session.
+func (m *DialogManager) Reset(sessionID string) error {
+	return m.store.Delete(sessionID)
+}
+
+func (m *DialogManager) finish(sessionID string, state *DialogState, msg *MessageResponse, status DialogStatus) (*DialogTurn, error) {
+	if err := m.store.Delete(sessionID); err != nil {
+		return nil, err
+	}
+
+	return &DialogTurn{
+		Status:  status,
+		Intent:  state.Intent,
+		Slots:   state.Slots,
+		Message: msg,
+	}, nil
+}
+
+// findEntities returns the entities of msg with the given key or name.
+func findEntities(msg *MessageResponse, name string) []MessageEntity {
+	if entities := msg.Entities[name]; len(entities) > 0 {
+		return entities
+	}
+
+	var found []MessageEntity
+	for _, entities := range msg.Entities {
+		for _, e := range entities {
+			if e.Name == name {
+				found = append(found, e)
+			}
+		}
+	}
+	return found
+}
+
+// bestEntity returns the entity with the given key or name having the
+// highest confidence.
+func bestEntity(msg *MessageResponse, name string) (MessageEntity, bool) {
+	entities := findEntities(msg, name)
+	if len(entities) == 0 {
+		return MessageEntity{}, false
+	}
+
+	best := entities[0]
+	for _, e := range entities[1:] {
+		if e.Confidence > best.Confidence {
+			best = e
+		}
+	}
+	return best, true
+}
+
+func fillPrompt(prompt string, slots map[string]MessageEntity) string {
+	if len(slots) == 0 {
+		return prompt
+	}
+
+	pairs := make([]string, 0, len(slots)*2)
+	for name, e := range slots {
+		pairs = append(pairs, "{"+name+"}", e.Body)
+	}
+	return strings.NewReplacer(pairs...).Replace(prompt)
+}
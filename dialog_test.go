@@ -0,0 +1,132 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDialogManager() *DialogManager {
+	m := NewDialogManager(nil, nil)
+	m.Threshold = 0.5
+	m.Register(DialogIntent{
+		Name: "book_flight",
+		Slots: []Slot{
+			{Name: "date", Entity: "wit$datetime", Prompt: "When do you want to fly?"},
+			{Name: "destination", Entity: "wit$location:destination", Prompt: "Where to on {date}?"},
+		},
+		ConfirmPrompt: "Book a flight to {destination} on {date}?",
+	})
+	return m
+}
+
+func intentMessage(name string, confidence float64) *MessageResponse {
+	return &MessageResponse{Intents: []MessageIntent{{Name: name, Confidence: confidence}}}
+}
+
+func TestDialogManagerSlotFilling(t *testing.T) {
+	m := newTestDialogManager()
+
+	turn, err := m.Advance("s1", intentMessage("book_flight", 0.9))
+	require.NoError(t, err)
+	require.Equal(t, DialogPrompt, turn.Status)
+	require.Equal(t, "date", turn.Slot)
+	require.Equal(t, "When do you want to fly?", turn.Prompt)
+
+	turn, err = m.Advance("s1", &MessageResponse{
+		Entities: map[string][]MessageEntity{
+			"wit$datetime:datetime": {{Name: "wit$datetime", Body: "tomorrow", Confidence: 0.9}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, DialogPrompt, turn.Status)
+	require.Equal(t, "destination", turn.Slot)
+	require.Equal(t, "Where to on tomorrow?", turn.Prompt)
+
+	turn, err = m.Advance("s1", &MessageResponse{
+		Entities: map[string][]MessageEntity{
+			"wit$location:destination": {{Name: "wit$location", Body: "Paris", Confidence: 0.9}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, DialogConfirm, turn.Status)
+	require.Equal(t, "Book a flight to Paris on tomorrow?", turn.Prompt)
+
+	turn, err = m.Advance("s1", intentMessage("wit$confirmation", 0.9))
+	require.NoError(t, err)
+	require.Equal(t, DialogComplete, turn.Status)
+	require.Equal(t, "book_flight", turn.Intent)
+	require.Equal(t, "Paris", turn.Slots["destination"].Body)
+
+	turn, err = m.Advance("s1", intentMessage("wit$confirmation", 0.9))
+	require.NoError(t, err)
+	require.Equal(t, DialogNoMatch, turn.Status)
+}
+
+func TestDialogManagerCancel(t *testing.T) {
+	m := newTestDialogManager()
+
+	turn, err := m.Advance("s1", intentMessage("book_flight", 0.9))
+	require.NoError(t, err)
+	require.Equal(t, DialogPrompt, turn.Status)
+
+	turn, err = m.Advance("s1", intentMessage("wit$cancel", 0.9))
+	require.NoError(t, err)
+	require.Equal(t, DialogCancelled, turn.Status)
+
+	state, err := m.store.Load("s1")
+	require.NoError(t, err)
+	require.Nil(t, state)
+}
+
+func TestDialogManagerDeny(t *testing.T) {
+	m := newTestDialogManager()
+
+	turn, err := m.Advance("s1", &MessageResponse{
+		Intents: []MessageIntent{{Name: "book_flight", Confidence: 0.9}},
+		Entities: map[string][]MessageEntity{
+			"wit$datetime:datetime":    {{Name: "wit$datetime", Body: "today"}},
+			"wit$location:destination": {{Name: "wit$location", Body: "Rome"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, DialogConfirm, turn.Status)
+
+	turn, err = m.Advance("s1", intentMessage("wit$negation", 0.9))
+	require.NoError(t, err)
+	require.Equal(t, DialogCancelled, turn.Status)
+}
+
+func TestDialogManagerLowConfidence(t *testing.T) {
+	m := newTestDialogManager()
+
+	turn, err := m.Advance("s1", intentMessage("book_flight", 0.2))
+	require.NoError(t, err)
+	require.Equal(t, DialogNoMatch, turn.Status)
+}
+
+func TestDialogManagerHandle(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{
+			"text": "book a flight",
+			"intents": [{"id": "i1", "name": "book_flight", "confidence": 0.9}]
+		}`))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	m := newTestDialogManager()
+	m.client = c
+
+	turn, err := m.Handle(context.Background(), "s1", "book a flight")
+	require.NoError(t, err)
+	require.Equal(t, DialogPrompt, turn.Status)
+	require.Equal(t, "book a flight", turn.Message.Text)
+}
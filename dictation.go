@@ -3,7 +3,8 @@
 package witai
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"io"
 	"net/http"
 )
@@ -25,27 +26,90 @@ type DictationSpeech struct {
 }
 
 type DictationResponse struct {
-	Speech DictationSpeech `json:"speech"`
-	Text   string          `json:"text"`
-	Type   string          `json:"type"`
+	Speech  DictationSpeech `json:"speech"`
+	Text    string          `json:"text"`
+	Type    string          `json:"type"`
+	IsFinal bool            `json:"is_final"`
+}
+
+// Final - whether the response is a final transcription.
+func (r *DictationResponse) Final() bool {
+	return r.IsFinal || r.Type == string(FinalTranscription)
+}
+
+// DictationStream - responses of a /dictation request, see StreamDictation.
+type DictationStream struct {
+	stream *jsonStream
 }
 
 // Dictation - Returns the text transcription from an audio file or stream.
+//
+// The last final transcription is returned, or the last one if none is
+// final. Use StreamDictation to get all of them. The client timeout applies
+// to the whole request, see SetHTTPClient.
 func (c *Client) Dictation(req DictationRequest) (*DictationResponse, error) {
-	resp, err := c.request(http.MethodPost, "/dictation", req.ContentType, req.File)
+	ctx, cancel := c.timeoutContext()
+	defer cancel()
+
+	return c.dictationContext(ctx, req)
+}
+
+// dictationContext returns the last final transcription of req, or the last
+// one if none is final.
+func (c *Client) dictationContext(ctx context.Context, req DictationRequest) (*DictationResponse, error) {
+	stream, err := c.StreamDictation(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	defer resp.Close()
+	defer stream.Close()
 
-	var msgResp *DictationResponse
-	decoder := json.NewDecoder(resp)
+	var last, final *DictationResponse
 	for {
-		err := decoder.Decode(&msgResp)
-		if err != nil {
+		resp, err := stream.Next()
+		if err == io.EOF {
 			break
 		}
+		if err != nil {
+			return nil, err
+		}
+		last = resp
+		if resp.Final() {
+			final = resp
+		}
+	}
+
+	if final != nil {
+		return final, nil
 	}
-	return msgResp, err
+	if last == nil {
+		return nil, errors.New("no transcription in response")
+	}
+	return last, nil
+}
+
+// StreamDictation - sends audio and returns the stream of partial and final
+// transcriptions, as they are produced. Cancelling ctx aborts the request.
+func (c *Client) StreamDictation(ctx context.Context, req DictationRequest) (*DictationStream, error) {
+	resp, err := c.streaming().requestContext(ctx, http.MethodPost, "/dictation", req.ContentType, req.File)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DictationStream{stream: newJSONStream(resp)}, nil
+}
+
+// Next - returns the next transcription. It returns io.EOF after the last
+// one, any other error means the stream ended abnormally.
+func (s *DictationStream) Next() (*DictationResponse, error) {
+	var resp *DictationResponse
+	if err := s.stream.next(&resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Close - stops reading transcriptions and releases the connection.
+func (s *DictationStream) Close() error {
+	return s.stream.close()
 }
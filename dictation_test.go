@@ -0,0 +1,157 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const dictationStreamResponse = `{"text": "hello", "type": "PARTIAL_TRANSCRIPTION", "is_final": false, "speech": {"confidence": 0.5, "tokens": [{"start": 0, "end": 400, "token": "hello"}]}}
+{"text": "hello world", "type": "FINAL_TRANSCRIPTION", "is_final": true, "speech": {"confidence": 0.9, "tokens": [{"start": 0, "end": 400, "token": "hello"}, {"start": 400, "end": 900, "token": "world"}]}}
+`
+
+func TestStreamDictation(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(dictationStreamResponse))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+	stream, err := c.StreamDictation(context.Background(), DictationRequest{
+		File:        bytes.NewReader(nil),
+		ContentType: "audio/wav",
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	resp, err := stream.Next()
+	require.NoError(t, err)
+	require.False(t, resp.Final())
+	require.Equal(t, "hello", resp.Text)
+
+	resp, err = stream.Next()
+	require.NoError(t, err)
+	require.True(t, resp.Final())
+	require.Equal(t, 0.9, resp.Speech.Confidence)
+	require.Len(t, resp.Speech.Tokens, 2)
+
+	_, err = stream.Next()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestDictation(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(dictationStreamResponse))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+	resp, err := c.Dictation(DictationRequest{File: bytes.NewReader(nil), ContentType: "audio/wav"})
+	require.NoError(t, err)
+	require.Equal(t, "hello world", resp.Text)
+}
+
+func TestDictationDecodeError(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(`{"text": "hello", "type": "PARTIAL_TRANSCRIPTION"} {"text": `))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+	_, err := c.Dictation(DictationRequest{File: bytes.NewReader(nil), ContentType: "audio/wav"})
+	require.Error(t, err)
+}
+
+func TestStreamDictationCancel(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(`{"text": "hello", "type": "PARTIAL_TRANSCRIPTION"}`))
+		res.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := c.StreamDictation(ctx, DictationRequest{File: bytes.NewReader(nil), ContentType: "audio/wav"})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	_, err = stream.Next()
+	require.NoError(t, err)
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = stream.Next()
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestStreamDictationSlowResponse(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(`{"text": "hello", "type": "PARTIAL_TRANSCRIPTION"}` + "\n"))
+		res.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		res.Write([]byte(`{"text": "hello world", "type": "FINAL_TRANSCRIPTION", "is_final": true}`))
+	}))
+	defer testServer.Close()
+
+	// the stream outlives the client timeout
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+	c.SetHTTPClient(&http.Client{Timeout: 20 * time.Millisecond})
+	stream, err := c.StreamDictation(context.Background(), DictationRequest{File: bytes.NewReader(nil), ContentType: "audio/wav"})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	_, err = stream.Next()
+	require.NoError(t, err)
+	resp, err := stream.Next()
+	require.NoError(t, err)
+	require.Equal(t, "hello world", resp.Text)
+}
+
+func TestDictationTimeout(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(`{"text": "hello", "type": "PARTIAL_TRANSCRIPTION"}` + "\n"))
+		res.(http.Flusher).Flush()
+		select {
+		case <-req.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer testServer.Close()
+
+	// Dictation has no context, the client timeout still applies
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+	c.SetHTTPClient(&http.Client{Timeout: 20 * time.Millisecond})
+	start := time.Now()
+	_, err := c.Dictation(DictationRequest{File: bytes.NewReader(nil), ContentType: "audio/wav"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestDictationReturnsFinal(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(dictationStreamResponse))
+		res.Write([]byte(`{"text": "hello wor", "type": "PARTIAL_TRANSCRIPTION"}`))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+	resp, err := c.Dictation(DictationRequest{File: bytes.NewReader(nil), ContentType: "audio/wav"})
+	require.NoError(t, err)
+	require.Equal(t, "hello world", resp.Text)
+}
@@ -3,10 +3,14 @@
 package witai
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
 )
 
 // Locales - https://wit.ai/docs/http#get__language_link
@@ -20,9 +24,44 @@ type Locale struct {
 	Confidence float64 `json:"confidence"`
 }
 
+// DetectRequest - https://wit.ai/docs/http#get__language_link
+type DetectRequest struct {
+	Text string
+	// N is the maximum number of locales to return, between 1 and 8.
+	// The API default is used when zero.
+	N int
+	// MinConfidence filters out locales with a lower confidence.
+	MinConfidence float64
+}
+
+// DetectResult - result of one item of DetectBatch.
+type DetectResult struct {
+	Locales *Locales
+	Err     error
+}
+
 // Detect - returns the detected languages from query - https://wit.ai/docs/http#get__language_link
 func (c *Client) Detect(text string) (*Locales, error) {
-	resp, err := c.request(http.MethodGet, fmt.Sprintf("/language?q=%s", url.PathEscape(text)), "application/json", nil)
+	return c.DetectContext(context.Background(), &DetectRequest{Text: text})
+}
+
+// DetectContext - returns the detected languages of req.Text.
+//
+// https://wit.ai/docs/http#get__language_link
+func (c *Client) DetectContext(ctx context.Context, req *DetectRequest) (*Locales, error) {
+	if req == nil {
+		return nil, errors.New("invalid request")
+	}
+	if req.N < 0 || req.N > MaxN {
+		return nil, fmt.Errorf("invalid n %d, must be between 0 and %d", req.N, MaxN)
+	}
+
+	q := fmt.Sprintf("/language?q=%s", url.QueryEscape(req.Text))
+	if req.N != 0 {
+		q += fmt.Sprintf("&n=%d", req.N)
+	}
+
+	resp, err := c.requestContext(ctx, http.MethodGet, q, "application/json", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -34,6 +73,77 @@ func (c *Client) Detect(text string) (*Locales, error) {
 	if err != nil {
 		return nil, err
 	}
+	if locales == nil {
+		return nil, errors.New("no locales in response")
+	}
+
+	if req.MinConfidence > 0 {
+		detected := locales.DetectedLocales[:0]
+		for _, l := range locales.DetectedLocales {
+			if l.Confidence >= req.MinConfidence {
+				detected = append(detected, l)
+			}
+		}
+		locales.DetectedLocales = detected
+	}
 
 	return locales, nil
 }
+
+// DetectBatch - detects the languages of all requests using a bounded pool
+// of workers. Results are returned in the order of reqs, see ParseBatch.
+func (c *Client) DetectBatch(ctx context.Context, reqs []DetectRequest, opts *BatchOptions) ([]DetectResult, error) {
+	results := make([]DetectResult, len(reqs))
+	runBatch(ctx, len(reqs), opts, func(i int) {
+		results[i].Locales, results[i].Err = c.DetectContext(ctx, &reqs[i])
+	})
+
+	return results, ctx.Err()
+}
+
+var localeTagRegexp = regexp.MustCompile(`^([a-zA-Z]{2,3})(?:[_-]([a-zA-Z]{2}))?$`)
+
+// LocaleTag - typed representation of a Wit locale such as "en_US".
+//
+// Wit uses the region "XX" when it's unknown, LocaleTag leaves it empty.
+// String returns a BCP 47 tag which can be given to i18n libraries, e.g.
+// language.Parse(tag.String()) from golang.org/x/text/language.
+type LocaleTag struct {
+	Language string
+	Region   string
+}
+
+// ParseLocaleTag - parses locales like "en_US", "en-us", "en_XX" or "en".
+func ParseLocaleTag(locale string) (LocaleTag, error) {
+	m := localeTagRegexp.FindStringSubmatch(locale)
+	if m == nil {
+		return LocaleTag{}, fmt.Errorf("invalid locale %q", locale)
+	}
+
+	tag := LocaleTag{Language: strings.ToLower(m[1]), Region: strings.ToUpper(m[2])}
+	if tag.Region == "XX" {
+		tag.Region = ""
+	}
+	return tag, nil
+}
+
+// Tag - parses the locale.
+func (l Locale) Tag() (LocaleTag, error) {
+	return ParseLocaleTag(l.Locale)
+}
+
+// String - BCP 47 representation, "en-US" or "en".
+func (t LocaleTag) String() string {
+	if t.Region == "" {
+		return t.Language
+	}
+	return t.Language + "-" + t.Region
+}
+
+// WitLocale - representation used by Wit, e.g. in MessageContext.Locale.
+func (t LocaleTag) WitLocale() string {
+	if t.Region == "" {
+		return t.Language
+	}
+	return t.Language + "_" + t.Region
+}
@@ -3,9 +3,13 @@
 package witai
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestLocales(t *testing.T) {
@@ -35,3 +39,95 @@ func TestLocales(t *testing.T) {
 		t.Fatalf("lang=en expected, got %v", l.DetectedLocales[0].Locale)
 	}
 }
+
+func TestDetectContext(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if q := req.URL.Query().Get("q"); q != "fish & chips + tea" {
+			t.Errorf("unexpected query %v", q)
+		}
+		if n := req.URL.Query().Get("n"); n != "3" {
+			t.Errorf("unexpected n %v", n)
+		}
+		_, _ = res.Write([]byte(`{
+			"detected_locales": [
+				{"locale": "en_XX", "confidence": 0.8},
+				{"locale": "fr_XX", "confidence": 0.15},
+				{"locale": "es_XX", "confidence": 0.05}
+			]
+		}`))
+	}))
+	defer func() { testServer.Close() }()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+	l, err := c.DetectContext(context.Background(), &DetectRequest{
+		Text:          "fish & chips + tea",
+		N:             3,
+		MinConfidence: 0.1,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, []Locale{
+		{Locale: "en_XX", Confidence: 0.8},
+		{Locale: "fr_XX", Confidence: 0.15},
+	}, l.DetectedLocales)
+
+	_, err = c.DetectContext(context.Background(), &DetectRequest{Text: "hi", N: 9})
+	require.EqualError(t, err, "invalid n 9, must be between 0 and 8")
+}
+
+func TestDetectContextNullResponse(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		_, _ = res.Write([]byte(`null`))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+	_, err := c.DetectContext(context.Background(), &DetectRequest{Text: "hi", MinConfidence: 0.5})
+	require.EqualError(t, err, "no locales in response")
+}
+
+func TestDetectBatch(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(res, `{"detected_locales": [{"locale": %q, "confidence": 1}]}`, req.URL.Query().Get("q"))
+	}))
+	defer func() { testServer.Close() }()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+	results, err := c.DetectBatch(context.Background(), []DetectRequest{
+		{Text: "en_XX"}, {Text: "fr_XX"}, {Text: "de_XX"},
+	}, &BatchOptions{Concurrency: 2})
+
+	require.NoError(t, err)
+	for i, want := range []string{"en_XX", "fr_XX", "de_XX"} {
+		require.NoError(t, results[i].Err)
+		require.Equal(t, want, results[i].Locales.DetectedLocales[0].Locale)
+	}
+}
+
+func TestParseLocaleTag(t *testing.T) {
+	tests := []struct {
+		locale string
+		tag    LocaleTag
+		bcp47  string
+		wit    string
+	}{
+		{"en_US", LocaleTag{"en", "US"}, "en-US", "en_US"},
+		{"en-us", LocaleTag{"en", "US"}, "en-US", "en_US"},
+		{"fr_XX", LocaleTag{"fr", ""}, "fr", "fr"},
+		{"pt", LocaleTag{"pt", ""}, "pt", "pt"},
+	}
+
+	for _, tt := range tests {
+		tag, err := Locale{Locale: tt.locale}.Tag()
+		require.NoError(t, err)
+		require.Equal(t, tt.tag, tag)
+		require.Equal(t, tt.bcp47, tag.String())
+		require.Equal(t, tt.wit, tag.WitLocale())
+	}
+
+	_, err := ParseLocaleTag("english")
+	require.Error(t, err)
+}
@@ -0,0 +1,213 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultLocaleThreshold - minimum detection confidence used by LocaleRouter.
+const DefaultLocaleThreshold = 0.5
+
+// DefaultLocaleMaxUsers - number of users whose language LocaleRouter
+// remembers.
+const DefaultLocaleMaxUsers = 10000
+
+// LocaleRouter - forwards messages to the app matching their language.
+//
+// Wit apps have a single language: the router detects the language of the
+// message and parses it with the client registered for that language.
+type LocaleRouter struct {
+	// Threshold is the minimum confidence of a detected locale.
+	Threshold float64
+	// MemoryTTL is how long the language of a user is remembered. The
+	// language is remembered until Forget is called when zero.
+	MemoryTTL time.Duration
+	// MaxUsers is the maximum number of remembered users, the least
+	// recently detected are forgotten first. No limit when zero.
+	MaxUsers int
+
+	detector *Client
+	fallback *Client
+	mu       sync.RWMutex
+	clients  map[string]*Client
+	memory   map[string]*list.Element
+	order    *list.List // of *userLocale, least recently detected first
+}
+
+type userLocale struct {
+	userID string
+	locale string
+	at     time.Time
+}
+
+// NewLocaleRouter returns a router detecting languages with detector and
+// parsing messages in unknown languages with fallback.
+func NewLocaleRouter(detector *Client, fallback *Client) *LocaleRouter {
+	return &LocaleRouter{
+		Threshold: DefaultLocaleThreshold,
+		MaxUsers:  DefaultLocaleMaxUsers,
+		detector:  detector,
+		fallback:  fallback,
+		clients:   make(map[string]*Client),
+		memory:    make(map[string]*list.Element),
+		order:     list.New(),
+	}
+}
+
+// Register - sets the client of the app for the given language, such as
+// "en" or "fr". A locale like "en_US" registers its language.
+func (r *LocaleRouter) Register(lang string, c *Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients[localeLanguage(lang)] = c
+}
+
+// Forget - drops the remembered language of a user.
+func (r *LocaleRouter) Forget(userID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.memory[userID]; ok {
+		r.forget(e)
+	}
+}
+
+// Route - returns the locale of text and the client to parse it with. The
+// locale is empty when the fallback client is used. When userID is not empty
+// the language detected for the user is reused on later calls.
+func (r *LocaleRouter) Route(ctx context.Context, userID string, text string) (string, *Client, error) {
+	if userID != "" {
+		if locale, ok := r.remembered(userID); ok {
+			if c := r.client(locale); c != nil {
+				return locale, c, nil
+			}
+		}
+	}
+
+	if r.detector == nil {
+		return "", nil, errors.New("locale router has no detector")
+	}
+
+	locales, err := r.detector.DetectContext(ctx, &DetectRequest{Text: text, MinConfidence: r.Threshold})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var best *Locale
+	for i, l := range locales.DetectedLocales {
+		if r.client(l.Locale) == nil {
+			continue
+		}
+		if best == nil || l.Confidence > best.Confidence {
+			best = &locales.DetectedLocales[i]
+		}
+	}
+
+	if best == nil {
+		if r.fallback == nil {
+			return "", nil, errors.New("no app for the detected language")
+		}
+		return "", r.fallback, nil
+	}
+
+	if userID != "" {
+		r.remember(userID, best.Locale)
+	}
+
+	return best.Locale, r.client(best.Locale), nil
+}
+
+// Parse - parses req with the app matching its language. The detected
+// locale is set in the message context unless the request already has one.
+func (r *LocaleRouter) Parse(ctx context.Context, userID string, req *MessageRequest) (*MessageResponse, error) {
+	if req == nil {
+		return nil, errors.New("invalid request")
+	}
+
+	locale, c, err := r.Route(ctx, userID, req.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	routed := *req
+	if locale != "" && (req.Context == nil || req.Context.Locale == "") {
+		// Detected locales have a pseudo region, e.g. fr_XX.
+		if tag, err := ParseLocaleTag(locale); err == nil {
+			msgCtx := MessageContext{}
+			if req.Context != nil {
+				msgCtx = *req.Context
+			}
+			msgCtx.Locale = tag.WitLocale()
+			routed.Context = &msgCtx
+		}
+	}
+
+	return c.ParseContext(ctx, &routed)
+}
+
+// remembered returns the language of the user, forgetting it if expired.
+func (r *LocaleRouter) remembered(userID string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.memory[userID]
+	if !ok {
+		return "", false
+	}
+	if r.expired(e.Value.(*userLocale), time.Now()) {
+		r.forget(e)
+		return "", false
+	}
+	return e.Value.(*userLocale).locale, true
+}
+
+// remember records the language of the user, and forgets the expired users
+// and the ones over MaxUsers.
+func (r *LocaleRouter) remember(userID string, locale string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if e, ok := r.memory[userID]; ok {
+		u := e.Value.(*userLocale)
+		u.locale, u.at = locale, now
+		r.order.MoveToBack(e)
+	} else {
+		r.memory[userID] = r.order.PushBack(&userLocale{userID: userID, locale: locale, at: now})
+	}
+
+	for e := r.order.Front(); e != nil; e = r.order.Front() {
+		if !r.expired(e.Value.(*userLocale), now) && (r.MaxUsers <= 0 || r.order.Len() <= r.MaxUsers) {
+			break
+		}
+		r.forget(e)
+	}
+}
+
+func (r *LocaleRouter) expired(u *userLocale, now time.Time) bool {
+	return r.MemoryTTL > 0 && now.Sub(u.at) >= r.MemoryTTL
+}
+
+func (r *LocaleRouter) forget(e *list.Element) {
+	r.order.Remove(e)
+	delete(r.memory, e.Value.(*userLocale).userID)
+}
+
+func (r *LocaleRouter) client(locale string) *Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.clients[localeLanguage(locale)]
+}
+
+// localeLanguage returns the language part of a locale, "en" for "en_US".
+func localeLanguage(locale string) string {
+	tag, err := ParseLocaleTag(locale)
+	if err != nil {
+		return locale
+	}
+	return tag.Language
+}
@@ -0,0 +1,99 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newLocaleTestServer(t *testing.T, app string, detections *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/language":
+			atomic.AddInt32(detections, 1)
+			w.Write([]byte(`{"detected_locales": [
+				{"locale": "en_XX", "confidence": 0.3},
+				{"locale": "fr_XX", "confidence": 0.7},
+				{"locale": "de_XX", "confidence": 0.8}
+			]}`))
+		case "/message":
+			fmt.Fprintf(w, `{"msg_id": %q, "text": %q}`, app+":"+req.URL.Query().Get("context"), req.URL.Query().Get("q"))
+		default:
+			t.Errorf("unexpected path %v", req.URL.Path)
+		}
+	}))
+}
+
+func TestLocaleRouter(t *testing.T) {
+	var detections int32
+	servers := map[string]*httptest.Server{}
+	clients := map[string]*Client{}
+	for _, app := range []string{"detector", "fr", "en", "fallback"} {
+		servers[app] = newLocaleTestServer(t, app, &detections)
+		defer servers[app].Close()
+		clients[app] = NewClient(unitTestToken)
+		clients[app].APIBase = servers[app].URL
+	}
+
+	r := NewLocaleRouter(clients["detector"], clients["fallback"])
+	r.Register("fr", clients["fr"])
+	r.Register("en_US", clients["en"])
+
+	// de_XX has the best confidence but no app, en_XX is below threshold,
+	// the pseudo region of fr_XX isn't set in the context
+	msg, err := r.Parse(context.Background(), "user1", &MessageRequest{Query: "bonjour"})
+	require.NoError(t, err)
+	require.Equal(t, `fr:{"locale":"fr"}`, msg.ID)
+	require.Equal(t, int32(1), atomic.LoadInt32(&detections))
+
+	// the language of user1 is remembered
+	msg, err = r.Parse(context.Background(), "user1", &MessageRequest{
+		Query:   "salut",
+		Context: &MessageContext{Locale: "fr_FR"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `fr:{"locale":"fr_FR"}`, msg.ID)
+	require.Equal(t, int32(1), atomic.LoadInt32(&detections))
+
+	r.Forget("user1")
+	r.Threshold = 0.9
+	msg, err = r.Parse(context.Background(), "user1", &MessageRequest{Query: "hallo"})
+	require.NoError(t, err)
+	require.Equal(t, "fallback:", msg.ID)
+	require.Equal(t, int32(2), atomic.LoadInt32(&detections))
+}
+
+func TestLocaleRouterMemory(t *testing.T) {
+	r := NewLocaleRouter(NewClient(unitTestToken), NewClient(unitTestToken))
+	r.MaxUsers = 2
+	r.remember("user1", "fr")
+	r.remember("user2", "en")
+	r.remember("user1", "de")
+	r.remember("user3", "es")
+
+	// user2 is the least recently detected
+	require.Len(t, r.memory, 2)
+	_, ok := r.remembered("user2")
+	require.False(t, ok)
+	locale, ok := r.remembered("user1")
+	require.True(t, ok)
+	require.Equal(t, "de", locale)
+
+	// expired users are forgotten when read or when another is remembered
+	r.MemoryTTL = time.Millisecond
+	time.Sleep(2 * time.Millisecond)
+	_, ok = r.remembered("user1")
+	require.False(t, ok)
+	require.Len(t, r.memory, 1)
+	r.remember("user4", "it")
+	require.Len(t, r.memory, 1)
+	require.Equal(t, 1, r.order.Len())
+}
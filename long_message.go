@@ -0,0 +1,266 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// IntentMergePolicy - how ParseLong combines the intents of the chunks.
+type IntentMergePolicy string
+
+const (
+	// IntentMergeMax keeps the highest confidence of each intent
+	IntentMergeMax IntentMergePolicy = "max"
+	// IntentMergeFirst keeps the intents of the first chunk having any
+	IntentMergeFirst IntentMergePolicy = "first"
+	// IntentMergeAverage averages the confidence of each intent over all chunks
+	IntentMergeAverage IntentMergePolicy = "average"
+)
+
+// LongMessageOptions - options for ParseLong.
+type LongMessageOptions struct {
+	// MaxLength is the maximum number of characters per chunk,
+	// MaxQueryLength by default.
+	MaxLength int
+	// Concurrency is the number of chunks parsed in parallel, chunks
+	// are parsed one after the other by default.
+	Concurrency int
+	// IntentPolicy defaults to IntentMergeMax.
+	IntentPolicy IntentMergePolicy
+}
+
+// ParseLong - same as ParseContext, but queries over the length limit are
+// split at sentence boundaries and their chunks parsed separately.
+//
+// The chunk results are merged into a single response for the original text:
+// entity offsets are relative to it, traits keep their highest confidence and
+// intents are combined according to opts.IntentPolicy.
+func (c *Client) ParseLong(ctx context.Context, req *MessageRequest, opts *LongMessageOptions) (*MessageResponse, error) {
+	if req == nil {
+		return nil, errors.New("invalid request")
+	}
+	if opts == nil {
+		opts = &LongMessageOptions{}
+	}
+
+	maxLength := opts.MaxLength
+	if maxLength <= 0 || maxLength > MaxQueryLength {
+		maxLength = MaxQueryLength
+	}
+	if CharCount(req.Query) <= maxLength {
+		return c.ParseContext(ctx, req)
+	}
+
+	chunks := splitText(req.Query, maxLength)
+	reqs := make([]MessageRequest, len(chunks))
+	for i, chunk := range chunks {
+		reqs[i] = *req
+		reqs[i].Query, _ = chunk.Text(req.Query)
+	}
+
+	// Check the whole request before sending anything.
+	for i := range reqs {
+		if err := reqs[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	results, err := c.ParseBatch(ctx, reqs, &BatchOptions{Concurrency: concurrency})
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*MessageResponse, len(results))
+	for i, r := range results {
+		if r.Err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", i, r.Err)
+		}
+		responses[i] = r.Response
+	}
+
+	msg := mergeMessageResponses(req.Query, chunks, responses, opts.IntentPolicy)
+	if req.N > 0 && len(msg.Intents) > req.N {
+		msg.Intents = msg.Intents[:req.N]
+	}
+
+	return msg, nil
+}
+
+// splitText splits text into chunks of at most max characters, preferably
+// at sentence boundaries, then at spaces. Surrounding spaces are not part of
+// the chunks.
+func splitText(text string, max int) []Span {
+	runes := []rune(text)
+
+	var sentences []Span
+	start := 0
+	for i, r := range runes {
+		end := i + 1
+		if !isSentenceEnd(r) || (end < len(runes) && !unicode.IsSpace(runes[end])) {
+			continue
+		}
+		sentences = append(sentences, Span{Start: start, End: end})
+		start = end
+	}
+	if start < len(runes) {
+		sentences = append(sentences, Span{Start: start, End: len(runes)})
+	}
+
+	var chunks []Span
+	add := func(s Span) {
+		s = trimSpan(runes, s)
+		if s.Len() > 0 {
+			chunks = append(chunks, s)
+		}
+	}
+
+	current := Span{}
+	for _, s := range sentences {
+		if trimSpan(runes, Span{Start: current.Start, End: s.End}).Len() <= max {
+			current.End = s.End
+			continue
+		}
+
+		add(current)
+		current = s
+		for trimSpan(runes, current).Len() > max {
+			current = trimSpan(runes, current)
+			cut := current.Start + max
+			for i := cut; i > current.Start; i-- {
+				if unicode.IsSpace(runes[i]) {
+					cut = i
+					break
+				}
+			}
+			add(Span{Start: current.Start, End: cut})
+			current.Start = cut
+		}
+	}
+	add(current)
+
+	return chunks
+}
+
+func isSentenceEnd(r rune) bool {
+	return strings.ContainsRune(".!?…。！？", r)
+}
+
+func trimSpan(runes []rune, s Span) Span {
+	for s.Start < s.End && unicode.IsSpace(runes[s.Start]) {
+		s.Start++
+	}
+	for s.End > s.Start && unicode.IsSpace(runes[s.End-1]) {
+		s.End--
+	}
+	return s
+}
+
+func mergeMessageResponses(text string, chunks []Span, responses []*MessageResponse, policy IntentMergePolicy) *MessageResponse {
+	msg := &MessageResponse{
+		Text:     text,
+		Intents:  []MessageIntent{},
+		Entities: make(map[string][]MessageEntity),
+		Traits:   make(map[string][]MessageTrait),
+	}
+
+	firstWithIntents := -1
+	for i, resp := range responses {
+		if resp != nil && len(resp.Intents) > 0 {
+			firstWithIntents = i
+			break
+		}
+	}
+
+	intents := make(map[string]MessageIntent)
+	traits := make(map[string]map[string]MessageTrait)
+	for i, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		if msg.ID == "" {
+			msg.ID = resp.ID
+		}
+
+		for key, entities := range resp.Entities {
+			for _, e := range entities {
+				msg.Entities[key] = append(msg.Entities[key], shiftEntity(e, chunks[i].Start))
+			}
+		}
+
+		for name, values := range resp.Traits {
+			if traits[name] == nil {
+				traits[name] = make(map[string]MessageTrait)
+			}
+			for _, v := range values {
+				if cur, ok := traits[name][v.Value]; !ok || v.Confidence > cur.Confidence {
+					traits[name][v.Value] = v
+				}
+			}
+		}
+
+		for _, intent := range resp.Intents {
+			cur, ok := intents[intent.Name]
+			switch policy {
+			case IntentMergeFirst:
+				if i == firstWithIntents {
+					intents[intent.Name] = intent
+				}
+			case IntentMergeAverage:
+				if ok {
+					intent.Confidence += cur.Confidence
+				}
+				intents[intent.Name] = intent
+			default:
+				if !ok || intent.Confidence > cur.Confidence {
+					intents[intent.Name] = intent
+				}
+			}
+		}
+	}
+
+	for _, intent := range intents {
+		if policy == IntentMergeAverage {
+			intent.Confidence /= float64(len(responses))
+		}
+		msg.Intents = append(msg.Intents, intent)
+	}
+	sort.Slice(msg.Intents, func(i, j int) bool {
+		return msg.Intents[i].Confidence > msg.Intents[j].Confidence
+	})
+
+	for name, values := range traits {
+		for _, v := range values {
+			msg.Traits[name] = append(msg.Traits[name], v)
+		}
+		sort.Slice(msg.Traits[name], func(i, j int) bool {
+			return msg.Traits[name][i].Confidence > msg.Traits[name][j].Confidence
+		})
+	}
+
+	return msg
+}
+
+// shiftEntity moves the offsets of e and its nested entities by offset
+// characters.
+func shiftEntity(e MessageEntity, offset int) MessageEntity {
+	e.Start += offset
+	e.End += offset
+	if len(e.Entities) > 0 {
+		nested := make([]MessageEntity, len(e.Entities))
+		for i, sub := range e.Entities {
+			nested[i] = shiftEntity(sub, offset)
+		}
+		e.Entities = nested
+	}
+	return e
+}
@@ -0,0 +1,131 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitText(t *testing.T) {
+	text := "Hello there. How are you? I'm fine, thanks!  Pi is 3.14 exactly."
+
+	var got []string
+	for _, s := range splitText(text, 30) {
+		chunk, err := s.Text(text)
+		require.NoError(t, err)
+		got = append(got, chunk)
+	}
+	require.Equal(t, []string{
+		"Hello there. How are you?",
+		"I'm fine, thanks!",
+		"Pi is 3.14 exactly.",
+	}, got)
+
+	got = nil
+	long := "aaaa bbbb cccc ddddddddddddd"
+	for _, s := range splitText(long, 10) {
+		chunk, _ := s.Text(long)
+		got = append(got, chunk)
+	}
+	require.Equal(t, []string{"aaaa bbbb", "cccc", "dddddddddd", "ddd"}, got)
+}
+
+func TestMessageRequestValidate(t *testing.T) {
+	require.NoError(t, (&MessageRequest{Query: strings.Repeat("é", MaxQueryLength)}).Validate())
+
+	err := (&MessageRequest{Query: strings.Repeat("a", MaxQueryLength+1)}).Validate()
+	require.True(t, errors.Is(err, ErrQueryTooLong))
+
+	require.Error(t, (&MessageRequest{Query: "hi", N: MaxN + 1}).Validate())
+	// Contexts are sent as is, e.g. for hosts without zoneinfo.
+	require.NoError(t, (&MessageRequest{Query: "hi", Context: &MessageContext{Locale: "en-US", Timezone: "America/Los_Angeles"}}).Validate())
+}
+
+func TestParseValidatesBeforeRequest(t *testing.T) {
+	var calls int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	_, err := c.Parse(&MessageRequest{Query: strings.Repeat("a", MaxQueryLength+1)})
+	require.ErrorIs(t, err, ErrQueryTooLong)
+	require.Zero(t, atomic.LoadInt32(&calls))
+}
+
+func TestParseLong(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query().Get("q")
+		resp := MessageResponse{
+			ID:       "msg-" + string([]rune(q)[:3]),
+			Text:     q,
+			Entities: map[string][]MessageEntity{},
+			Traits: map[string][]MessageTrait{
+				"wit$sentiment": {{Value: "neutral", Confidence: 0.5}},
+			},
+		}
+		if i := strings.Index(q, "Zürich"); i >= 0 {
+			start, _ := CharOffset(q, i)
+			resp.Intents = []MessageIntent{{Name: "travel", Confidence: 0.6}}
+			resp.Entities["wit$location:location"] = []MessageEntity{
+				{Name: "wit$location", Start: start, End: start + 6, Body: "Zürich"},
+			}
+		} else {
+			resp.Intents = []MessageIntent{{Name: "greet", Confidence: 0.9}}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	text := "Grüß dich! 👋 Ich möchte nach Zürich."
+
+	msg, err := c.ParseLong(context.Background(), &MessageRequest{Query: text}, &LongMessageOptions{
+		MaxLength:   20,
+		Concurrency: 2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, text, msg.Text)
+	require.Equal(t, "msg-Grü", msg.ID)
+	require.Equal(t, []MessageIntent{
+		{Name: "greet", Confidence: 0.9},
+		{Name: "travel", Confidence: 0.6},
+	}, msg.Intents)
+	require.Len(t, msg.Traits["wit$sentiment"], 1)
+
+	location := msg.Entities["wit$location:location"][0]
+	body, err := location.Span().Text(text)
+	require.NoError(t, err)
+	require.Equal(t, "Zürich", body)
+
+	msg, err = c.ParseLong(context.Background(), &MessageRequest{Query: text}, &LongMessageOptions{
+		MaxLength:    20,
+		IntentPolicy: IntentMergeAverage,
+	})
+	require.NoError(t, err)
+	// 3 chunks: "Grüß dich!", "👋 Ich möchte nach" and "Zürich."
+	require.Equal(t, "greet", msg.Intents[0].Name)
+	require.InDelta(t, 0.6, msg.Intents[0].Confidence, 1e-9)
+	require.InDelta(t, 0.2, msg.Intents[1].Confidence, 1e-9)
+
+	msg, err = c.ParseLong(context.Background(), &MessageRequest{Query: text}, &LongMessageOptions{
+		MaxLength:    20,
+		IntentPolicy: IntentMergeFirst,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []MessageIntent{{Name: "greet", Confidence: 0.9}}, msg.Intents)
+}
@@ -3,6 +3,7 @@
 package witai
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,17 @@ import (
 	"net/url"
 )
 
+const (
+	// MaxQueryLength - maximum number of characters of MessageRequest.Query
+	MaxQueryLength = 280
+	// MaxN - maximum value of MessageRequest.N
+	MaxN = 8
+)
+
+// ErrQueryTooLong is returned for queries over MaxQueryLength characters.
+// Use ParseLong to parse longer texts.
+var ErrQueryTooLong = errors.New("query too long")
+
 // MessageResponse - https://wit.ai/docs/http/#get__message_link
 type MessageResponse struct {
 	ID       string                     `json:"msg_id"`
@@ -51,13 +63,18 @@ type MessageIntent struct {
 
 // MessageRequest - https://wit.ai/docs/http/#get__message_link
 type MessageRequest struct {
-	Query   string          `json:"q"`
-	Tag     string          `json:"tag"`
-	N       int             `json:"n"`
-	Context *MessageContext `json:"context"`
-	Speech  *Speech         `json:"-"`
+	Query           string          `json:"q"`
+	Tag             string          `json:"tag"`
+	N               int             `json:"n"`
+	Context         *MessageContext `json:"context"`
+	DynamicEntities DynamicEntities `json:"entities,omitempty"`
+	Speech          *Speech         `json:"-"`
 }
 
+// DynamicEntities - keywords added to keyword entities for a single request,
+// keyed by entity name. https://wit.ai/docs/http/#dynamic_entities_link
+type DynamicEntities map[string][]EntityKeyword
+
 // Speech - https://wit.ai/docs/http/20170307#post__speech_link
 type Speech struct {
 	File        io.Reader `json:"file"`
@@ -65,13 +82,30 @@ type Speech struct {
 }
 
 // MessageContext - https://wit.ai/docs/http/20170307#context_link
+//
+// Use NewMessageContext to build a validated context from Go types.
 type MessageContext struct {
-	ReferenceTime string        `json:"reference_time"` // "2014-10-30T12:18:45-07:00"
-	Timezone      string        `json:"timezone"`
-	Locale        string        `json:"locale"`
+	ReferenceTime string        `json:"reference_time,omitempty"` // "2014-10-30T12:18:45-07:00"
+	Timezone      string        `json:"timezone,omitempty"`
+	Locale        string        `json:"locale,omitempty"`
 	Coords        MessageCoords `json:"coords"`
 }
 
+// MarshalJSON - omits unset coords, which Wit would otherwise read as the
+// 0,0 position.
+func (mc MessageContext) MarshalJSON() ([]byte, error) {
+	type messageContext MessageContext
+	out := struct {
+		messageContext
+		Coords *MessageCoords `json:"coords,omitempty"`
+	}{messageContext: messageContext(mc)}
+	if mc.Coords != (MessageCoords{}) {
+		out.Coords = &mc.Coords
+	}
+
+	return json.Marshal(out)
+}
+
 // MessageCoords - https://wit.ai/docs/http/20170307#context_link
 type MessageCoords struct {
 	Lat  float32 `json:"lat"`
@@ -80,13 +114,21 @@ type MessageCoords struct {
 
 // Parse - parses text and returns entities
 func (c *Client) Parse(req *MessageRequest) (*MessageResponse, error) {
+	return c.ParseContext(context.Background(), req)
+}
+
+// ParseContext - same as Parse, but the request is bound to ctx
+func (c *Client) ParseContext(ctx context.Context, req *MessageRequest) (*MessageResponse, error) {
 	if req == nil {
 		return nil, errors.New("invalid request")
 	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
 
 	q := buildParseQuery(req)
 
-	resp, err := c.request(http.MethodGet, "/message"+q, "application/json", nil)
+	resp, err := c.requestContext(ctx, http.MethodGet, "/message"+q, "application/json", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -99,25 +141,59 @@ func (c *Client) Parse(req *MessageRequest) (*MessageResponse, error) {
 	return msgResp, err
 }
 
-// Speech - sends audio file for parsing
+// Speech - sends audio file for parsing and returns the final understanding.
+// Use StreamSpeech to get partial results.
+//
+// The client timeout applies to the whole request, see SetHTTPClient.
 func (c *Client) Speech(req *MessageRequest) (*MessageResponse, error) {
-	if req == nil || req.Speech == nil {
-		return nil, errors.New("invalid request")
-	}
+	ctx, cancel := c.timeoutContext()
+	defer cancel()
 
-	q := buildParseQuery(req)
-
-	resp, err := c.request(http.MethodPost, "/speech"+q, req.Speech.ContentType, req.Speech.File)
+	stream, err := c.StreamSpeech(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	defer resp.Close()
+	defer stream.Close()
 
 	var msgResp *MessageResponse
-	decoder := json.NewDecoder(resp)
-	err = decoder.Decode(&msgResp)
-	return msgResp, err
+	for {
+		event, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if event.Understanding != nil {
+			msgResp = event.Understanding
+		}
+		if event.Type == FinalUnderstanding {
+			break
+		}
+	}
+
+	if msgResp == nil {
+		return nil, errors.New("no understanding in response")
+	}
+
+	return msgResp, nil
+}
+
+// Validate - checks the request against the limits of the API, so invalid
+// requests fail without a network call.
+//
+// The context is sent as is, use NewMessageContext or MessageContext.Validate
+// to check it.
+func (req *MessageRequest) Validate() error {
+	if n := CharCount(req.Query); n > MaxQueryLength {
+		return fmt.Errorf("%w: %d characters, max %d", ErrQueryTooLong, n, MaxQueryLength)
+	}
+	if req.N < 0 || req.N > MaxN {
+		return fmt.Errorf("invalid n %d, must be between 0 and %d", req.N, MaxN)
+	}
+	return nil
 }
 
 func buildParseQuery(req *MessageRequest) string {
@@ -126,7 +202,7 @@ func buildParseQuery(req *MessageRequest) string {
 		q += fmt.Sprintf("&n=%d", req.N)
 	}
 	if req.Tag != "" {
-		q += fmt.Sprintf("&tag=%s", req.Tag)
+		q += fmt.Sprintf("&tag=%s", url.QueryEscape(req.Tag))
 	}
 	if req.Context != nil {
 		b, _ := json.Marshal(req.Context)
@@ -134,6 +210,12 @@ func buildParseQuery(req *MessageRequest) string {
 			q += fmt.Sprintf("&context=%s", url.QueryEscape(string(b)))
 		}
 	}
+	if len(req.DynamicEntities) > 0 {
+		b, _ := json.Marshal(req.DynamicEntities)
+		if b != nil {
+			q += fmt.Sprintf("&entities=%s", url.QueryEscape(string(b)))
+		}
+	}
 
 	return q
 }
@@ -0,0 +1,116 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var localeRegexp = regexp.MustCompile(`^[a-z]{2,3}(_[A-Z]{2})?$`)
+
+// NewMessageContext - returns a context resolving relative dates such as
+// "tomorrow" for a user at reference time t in location loc.
+//
+// A zero t or a nil loc leave the corresponding field unset. loc must be an
+// IANA zone (e.g. time.LoadLocation("Europe/Paris")), time.Local is rejected
+// as its name doesn't identify a zone. locale is a language tag such as
+// "en_US" or "en-US", it may be empty.
+func NewMessageContext(t time.Time, loc *time.Location, locale string) (*MessageContext, error) {
+	mc := &MessageContext{}
+
+	if loc != nil {
+		if err := validateTimezone(loc.String()); err != nil {
+			return nil, err
+		}
+		mc.Timezone = loc.String()
+		if !t.IsZero() {
+			t = t.In(loc)
+		}
+	}
+
+	if !t.IsZero() {
+		mc.ReferenceTime = t.Format(time.RFC3339)
+	}
+
+	if locale != "" {
+		if err := mc.SetLocale(locale); err != nil {
+			return nil, err
+		}
+	}
+
+	return mc, nil
+}
+
+// SetLocale - validates and sets the locale, normalizing "en-us" to "en_US".
+func (mc *MessageContext) SetLocale(locale string) error {
+	normalized := normalizeLocale(locale)
+	if !localeRegexp.MatchString(normalized) {
+		return fmt.Errorf("invalid locale %q", locale)
+	}
+
+	mc.Locale = normalized
+	return nil
+}
+
+// SetCoords - validates and sets the user coordinates.
+func (mc *MessageContext) SetCoords(lat, long float32) error {
+	coords := MessageCoords{Lat: lat, Long: long}
+	if err := coords.validate(); err != nil {
+		return err
+	}
+
+	mc.Coords = coords
+	return nil
+}
+
+// Validate - checks all set fields have the format expected by Wit.
+func (mc *MessageContext) Validate() error {
+	if mc.ReferenceTime != "" {
+		if _, err := time.Parse(time.RFC3339, mc.ReferenceTime); err != nil {
+			return fmt.Errorf("invalid reference time %q: %s", mc.ReferenceTime, err.Error())
+		}
+	}
+	if mc.Timezone != "" {
+		if err := validateTimezone(mc.Timezone); err != nil {
+			return err
+		}
+	}
+	if mc.Locale != "" && !localeRegexp.MatchString(mc.Locale) {
+		return fmt.Errorf("invalid locale %q", mc.Locale)
+	}
+	if mc.Coords != (MessageCoords{}) {
+		return mc.Coords.validate()
+	}
+
+	return nil
+}
+
+func (c *MessageCoords) validate() error {
+	if c.Lat < -90 || c.Lat > 90 || c.Long < -180 || c.Long > 180 {
+		return fmt.Errorf("invalid coordinates %v,%v", c.Lat, c.Long)
+	}
+	return nil
+}
+
+// validateTimezone checks name is a loadable IANA zone. Programs running
+// without system zoneinfo should import time/tzdata.
+func validateTimezone(name string) error {
+	if name == "" || name == "Local" {
+		return fmt.Errorf("invalid timezone %q: an IANA zone name is required", name)
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("invalid timezone %q: %s", name, err.Error())
+	}
+	return nil
+}
+
+func normalizeLocale(locale string) string {
+	lang, region, found := strings.Cut(strings.ReplaceAll(locale, "-", "_"), "_")
+	if !found {
+		return strings.ToLower(lang)
+	}
+	return strings.ToLower(lang) + "_" + strings.ToUpper(region)
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMessageContext(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+
+	ref := time.Date(2014, 10, 30, 19, 18, 45, 0, time.UTC)
+	mc, err := NewMessageContext(ref, loc, "en-us")
+	require.NoError(t, err)
+	require.Equal(t, &MessageContext{
+		ReferenceTime: "2014-10-30T12:18:45-07:00",
+		Timezone:      "America/Los_Angeles",
+		Locale:        "en_US",
+	}, mc)
+	require.NoError(t, mc.Validate())
+
+	b, err := json.Marshal(mc)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"reference_time": "2014-10-30T12:18:45-07:00",
+		"timezone": "America/Los_Angeles",
+		"locale": "en_US"
+	}`, string(b))
+
+	require.NoError(t, mc.SetCoords(32.47104, -122.14703))
+	b, err = json.Marshal(mc)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"coords":{"lat":32.47104,"long":-122.14703}`)
+
+	var decoded MessageContext
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Equal(t, *mc, decoded)
+}
+
+func TestNewMessageContextEmpty(t *testing.T) {
+	mc, err := NewMessageContext(time.Time{}, nil, "")
+	require.NoError(t, err)
+
+	b, err := json.Marshal(mc)
+	require.NoError(t, err)
+	require.Equal(t, `{}`, string(b))
+}
+
+func TestNewMessageContextInvalid(t *testing.T) {
+	_, err := NewMessageContext(time.Now(), time.Local, "")
+	require.Error(t, err)
+
+	_, err = NewMessageContext(time.Now(), time.FixedZone("Nowhere/Town", 0), "")
+	require.Error(t, err)
+
+	_, err = NewMessageContext(time.Now(), nil, "english")
+	require.Error(t, err)
+
+	mc := &MessageContext{}
+	require.Error(t, mc.SetCoords(91, 0))
+}
+
+func TestMessageContextValidate(t *testing.T) {
+	require.NoError(t, (&MessageContext{Locale: "fr"}).Validate())
+	require.Error(t, (&MessageContext{ReferenceTime: "yesterday"}).Validate())
+	require.Error(t, (&MessageContext{Timezone: "Mars/Olympus_Mons"}).Validate())
+	require.Error(t, (&MessageContext{Locale: "en-US"}).Validate())
+	require.Error(t, (&MessageContext{Coords: MessageCoords{Long: 200}}).Validate())
+}
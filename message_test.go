@@ -177,3 +177,51 @@ func Test_buildParseQuery(t *testing.T) {
 		t.Fatalf("expected \n\tquery = %v \n\tgot = %v", want, got)
 	}
 }
+
+func Test_buildParseQueryDynamicEntities(t *testing.T) {
+	want := "?q=call+jane" +
+		"&entities=" +
+		"%7B%22contact%22%3A%5B" +
+		"%7B%22keyword%22%3A%22Jane%22%2C%22synonyms%22%3A%5B%22Jane%22%2C%22Janie%22%5D%7D" +
+		"%5D%7D"
+
+	got := buildParseQuery(&MessageRequest{
+		Query: "call jane",
+		DynamicEntities: DynamicEntities{
+			"contact": {{Keyword: "Jane", Synonyms: []string{"Jane", "Janie"}}},
+		},
+	})
+
+	if got != want {
+		t.Fatalf("expected \n\tquery = %v \n\tgot = %v", want, got)
+	}
+}
+
+func TestSpeechDynamicEntities(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		want := `{"contact":[{"keyword":"Jane","synonyms":["Jane"]}]}`
+		if got := req.URL.Query().Get("entities"); got != want {
+			t.Errorf("expected entities %v, got %v", want, got)
+		}
+		res.Write([]byte(`{"msg_id": "msg1"}`))
+	}))
+	defer func() { testServer.Close() }()
+
+	c := NewClient("token")
+	c.APIBase = testServer.URL
+	msg, err := c.Speech(&MessageRequest{
+		DynamicEntities: DynamicEntities{
+			"contact": {{Keyword: "Jane", Synonyms: []string{"Jane"}}},
+		},
+		Speech: &Speech{
+			File:        bytes.NewReader([]byte{}),
+			ContentType: "audio/wav",
+		},
+	})
+	if err != nil {
+		t.Fatalf("nil error expected, got %v", err)
+	}
+	if msg.ID != "msg1" {
+		t.Fatalf("msg_id=msg1 expected, got %v", msg.ID)
+	}
+}
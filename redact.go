@@ -0,0 +1,287 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Scrubber - pattern masked regardless of the entities detected by Wit.
+type Scrubber struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// Redactor - masks personal data in messages.
+//
+// Masked parts are replaced by placeholders such as "[wit$email_1]". Within
+// a text, the same value always gets the same placeholder, so redacted
+// messages can still be analyzed. Offsets of the remaining entities are
+// updated to match the redacted text.
+type Redactor struct {
+	// Entities lists the keys (e.g. "wit$email:email") or names
+	// (e.g. "wit$email") of the entities to mask.
+	Entities []string
+	// Scrubbers are applied to the text of requests and responses.
+	Scrubbers []Scrubber
+}
+
+// NewRedactor returns a Redactor masking the given entities.
+func NewRedactor(entities ...string) *Redactor {
+	return &Redactor{Entities: entities}
+}
+
+// AddScrubber - masks every match of pattern, e.g. card numbers.
+func (r *Redactor) AddScrubber(name string, pattern *regexp.Regexp) {
+	r.Scrubbers = append(r.Scrubbers, Scrubber{Name: name, Pattern: pattern})
+}
+
+// ScrubText - masks the scrubber matches of text.
+func (r *Redactor) ScrubText(text string) string {
+	redacted, _ := applyRedactions(text, r.scrubberRanges(text))
+	return redacted
+}
+
+// ScrubRequest - returns a copy of req with a scrubbed query.
+func (r *Redactor) ScrubRequest(req *MessageRequest) *MessageRequest {
+	scrubbed := *req
+	scrubbed.Query = r.ScrubText(req.Query)
+	return &scrubbed
+}
+
+// Parse - scrubs the request, parses it and redacts the response.
+func (r *Redactor) Parse(ctx context.Context, c *Client, req *MessageRequest) (*MessageResponse, error) {
+	if req == nil {
+		return nil, errors.New("invalid request")
+	}
+
+	msg, err := c.ParseContext(ctx, r.ScrubRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.RedactResponse(msg)
+}
+
+// RedactResponse - returns a copy of msg where the text, the masked entities
+// and the scrubber matches are replaced by placeholders.
+func (r *Redactor) RedactResponse(msg *MessageResponse) (*MessageResponse, error) {
+	if msg == nil {
+		return nil, errors.New("invalid message")
+	}
+
+	ranges := r.scrubberRanges(msg.Text)
+	for key, entities := range msg.Entities {
+		var err error
+		ranges, err = r.entityRanges(msg.Text, key, entities, ranges)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	text, redactions := applyRedactions(msg.Text, ranges)
+
+	redacted := *msg
+	redacted.Text = text
+	redacted.Entities = make(map[string][]MessageEntity, len(msg.Entities))
+	for key, entities := range msg.Entities {
+		out := make([]MessageEntity, len(entities))
+		for i, e := range entities {
+			out[i] = r.redactEntity(e, text, redactions, r.masks(key, e))
+		}
+		redacted.Entities[key] = out
+	}
+
+	return &redacted, nil
+}
+
+func (r *Redactor) masks(key string, e MessageEntity) bool {
+	for _, name := range r.Entities {
+		if name == key || name == e.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// entityRanges appends the spans of the masked entities, nested ones
+// included, to ranges.
+func (r *Redactor) entityRanges(text string, key string, entities []MessageEntity, ranges []redactionRange) ([]redactionRange, error) {
+	for _, e := range entities {
+		if r.masks(key, e) && e.Span().Len() > 0 {
+			if _, err := e.Span().Text(text); err != nil {
+				return nil, fmt.Errorf("entity %s: %s", e.Name, err.Error())
+			}
+			ranges = append(ranges, redactionRange{Span: e.Span(), label: e.Name})
+			continue
+		}
+
+		var err error
+		ranges, err = r.entityRanges(text, "", e.Entities, ranges)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ranges, nil
+}
+
+func (r *Redactor) scrubberRanges(text string) []redactionRange {
+	var ranges []redactionRange
+	for _, s := range r.Scrubbers {
+		for _, m := range s.Pattern.FindAllStringIndex(text, -1) {
+			span, err := SpanFromByteRange(text, m[0], m[1])
+			if err != nil || span.Len() == 0 {
+				continue
+			}
+			ranges = append(ranges, redactionRange{Span: span, label: s.Name})
+		}
+	}
+	return ranges
+}
+
+type redactionRange struct {
+	Span
+	label string
+}
+
+// redaction - a range of the original text replaced by a placeholder.
+type redaction struct {
+	original    Span
+	redacted    Span
+	value       string
+	placeholder string
+}
+
+// applyRedactions replaces the ranges of text by placeholders, merging
+// overlapping ranges.
+func applyRedactions(text string, ranges []redactionRange) (string, []redaction) {
+	if len(ranges) == 0 {
+		return text, nil
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].Start != ranges[j].Start {
+			return ranges[i].Start < ranges[j].Start
+		}
+		return ranges[i].Len() > ranges[j].Len()
+	})
+
+	merged := []redactionRange{ranges[0]}
+	for _, rg := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if rg.Start < last.End {
+			if rg.End > last.End {
+				last.End = rg.End
+			}
+			continue
+		}
+		merged = append(merged, rg)
+	}
+
+	var (
+		b          strings.Builder
+		redactions []redaction
+		last       int
+		offset     int
+		counts     = make(map[string]int)
+		seen       = make(map[string]string)
+	)
+	for _, rg := range merged {
+		start, end, err := rg.ByteRange(text)
+		if err != nil {
+			continue
+		}
+
+		value := text[start:end]
+		placeholder, ok := seen[rg.label+"\x00"+value]
+		if !ok {
+			counts[rg.label]++
+			placeholder = fmt.Sprintf("[%s_%d]", rg.label, counts[rg.label])
+			seen[rg.label+"\x00"+value] = placeholder
+		}
+
+		b.WriteString(text[last:start])
+		last = end
+
+		newStart := rg.Start + offset
+		offset += CharCount(placeholder) - rg.Len()
+		b.WriteString(placeholder)
+
+		redactions = append(redactions, redaction{
+			original:    rg.Span,
+			redacted:    Span{Start: newStart, End: newStart + CharCount(placeholder)},
+			value:       value,
+			placeholder: placeholder,
+		})
+	}
+	b.WriteString(text[last:])
+
+	return b.String(), redactions
+}
+
+// mapOffset converts an offset of the original text into an offset of the
+// redacted text. Offsets within a redaction move to its start, or to its
+// end if end is true.
+func mapOffset(offset int, end bool, redactions []redaction) int {
+	shift := 0
+	for _, rd := range redactions {
+		switch {
+		case rd.original.End <= offset:
+			shift = rd.redacted.End - rd.original.End
+		case rd.original.Start < offset:
+			if end {
+				return rd.redacted.End
+			}
+			return rd.redacted.Start
+		}
+	}
+	return offset + shift
+}
+
+func (r *Redactor) redactEntity(e MessageEntity, text string, redactions []redaction, masked bool) MessageEntity {
+	span := e.Span()
+	e.Start = mapOffset(span.Start, false, redactions)
+	e.End = mapOffset(span.End, true, redactions)
+
+	for _, rd := range redactions {
+		if rd.original.Contains(span) {
+			masked = true
+			break
+		}
+	}
+
+	if masked {
+		body, _ := e.Span().Text(text)
+		e.Body = body
+		e.Value = body
+		e.Entities = nil
+		e.Extra = nil
+		return e
+	}
+
+	// Masked text may also appear in the body and the resolved value of
+	// entities containing it.
+	for _, rd := range redactions {
+		if rd.original.Overlaps(span) {
+			if body, err := e.Span().Text(text); err == nil {
+				e.Body = body
+			}
+			e.Value = strings.ReplaceAll(e.Value, rd.value, rd.placeholder)
+		}
+	}
+
+	if len(e.Entities) > 0 {
+		nested := make([]MessageEntity, len(e.Entities))
+		for i, sub := range e.Entities {
+			nested[i] = r.redactEntity(sub, text, redactions, r.masks("", sub))
+		}
+		e.Entities = nested
+	}
+
+	return e
+}
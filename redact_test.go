@@ -0,0 +1,101 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactResponse(t *testing.T) {
+	text := "Écris à bob@example.com demain, pas à bob@example.com 🙏"
+	msg := &MessageResponse{
+		ID:   "msg1",
+		Text: text,
+		Entities: map[string][]MessageEntity{
+			"wit$email:email": {
+				{Name: "wit$email", Role: "email", Start: 8, End: 23, Body: "bob@example.com", Value: "bob@example.com"},
+				{Name: "wit$email", Role: "email", Start: 38, End: 53, Body: "bob@example.com", Value: "bob@example.com"},
+			},
+			"wit$datetime:datetime": {
+				{Name: "wit$datetime", Role: "datetime", Start: 24, End: 30, Body: "demain", Value: "2024-03-05T00:00:00.000-08:00"},
+			},
+		},
+	}
+
+	r := NewRedactor("wit$email")
+	got, err := r.RedactResponse(msg)
+	require.NoError(t, err)
+
+	require.Equal(t, "Écris à [wit$email_1] demain, pas à [wit$email_1] 🙏", got.Text)
+	require.Equal(t, text, msg.Text, "original message must not be modified")
+
+	for _, e := range got.Entities["wit$email:email"] {
+		require.Equal(t, "[wit$email_1]", e.Body)
+		require.Equal(t, "[wit$email_1]", e.Value)
+		body, err := e.Span().Text(got.Text)
+		require.NoError(t, err)
+		require.Equal(t, e.Body, body)
+	}
+
+	datetime := got.Entities["wit$datetime:datetime"][0]
+	body, err := datetime.Span().Text(got.Text)
+	require.NoError(t, err)
+	require.Equal(t, "demain", body)
+	require.Equal(t, "2024-03-05T00:00:00.000-08:00", datetime.Value)
+}
+
+func TestRedactResponseScrubbers(t *testing.T) {
+	msg := &MessageResponse{
+		Text: "pay 4111 1111 1111 1111 to Alice now",
+		Entities: map[string][]MessageEntity{
+			"contact:contact": {{Name: "contact", Start: 27, End: 32, Body: "Alice", Value: "Alice"}},
+			"payment:payment": {{
+				Name: "payment", Start: 0, End: 32, Body: "pay 4111 1111 1111 1111 to Alice",
+				Value: "4111 1111 1111 1111 -> Alice",
+				Entities: []MessageEntity{
+					{Name: "contact", Start: 27, End: 32, Body: "Alice", Value: "Alice"},
+				},
+			}},
+		},
+	}
+
+	r := NewRedactor("contact")
+	r.AddScrubber("card", regexp.MustCompile(`\d{4}( \d{4}){3}`))
+
+	got, err := r.RedactResponse(msg)
+	require.NoError(t, err)
+	require.Equal(t, "pay [card_1] to [contact_1] now", got.Text)
+
+	payment := got.Entities["payment:payment"][0]
+	require.Equal(t, "pay [card_1] to [contact_1]", payment.Body)
+	require.Equal(t, "[card_1] -> [contact_1]", payment.Value)
+	require.Equal(t, Span{16, 27}, payment.Entities[0].Span())
+	require.Equal(t, "[contact_1]", payment.Entities[0].Value)
+}
+
+func TestRedactorParse(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query().Get("q")
+		if q != "call [phone_1]" {
+			t.Errorf("unexpected query %v", q)
+		}
+		w.Write([]byte(`{"text": "call [phone_1]"}`))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	r := NewRedactor()
+	r.AddScrubber("phone", regexp.MustCompile(`\+?\d[\d ]{6,}\d`))
+
+	msg, err := r.Parse(context.Background(), c, &MessageRequest{Query: "call +33 6 12 34 56 78"})
+	require.NoError(t, err)
+	require.Equal(t, "call [phone_1]", msg.Text)
+}
@@ -0,0 +1,215 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNoRoute is returned by Router.Dispatch when a message matches no route
+// and no fallback handler is registered.
+var ErrNoRoute = errors.New("no route for message")
+
+// MessageHandler - handles a message dispatched by a Router.
+type MessageHandler interface {
+	HandleMessage(ctx context.Context, msg *MessageResponse) error
+}
+
+// MessageHandlerFunc - adapter to use ordinary functions as MessageHandler.
+type MessageHandlerFunc func(ctx context.Context, msg *MessageResponse) error
+
+// HandleMessage calls f(ctx, msg).
+func (f MessageHandlerFunc) HandleMessage(ctx context.Context, msg *MessageResponse) error {
+	return f(ctx, msg)
+}
+
+// Middleware - wraps a MessageHandler, e.g. for logging or metrics.
+type Middleware func(next MessageHandler) MessageHandler
+
+// Predicate - additional condition a message must satisfy to be routed.
+type Predicate func(msg *MessageResponse) bool
+
+// RouteOption - configures a single route.
+type RouteOption func(r *route)
+
+// WithThreshold overrides the router's confidence threshold for one intent.
+func WithThreshold(confidence float64) RouteOption {
+	return func(r *route) {
+		r.threshold = &confidence
+	}
+}
+
+// WithPredicate adds a predicate to a route. All predicates must match for
+// the route to be taken, otherwise the message goes to the fallback.
+func WithPredicate(p Predicate) RouteOption {
+	return func(r *route) {
+		r.predicates = append(r.predicates, p)
+	}
+}
+
+// HasEntity matches messages containing at least one entity with the given
+// key (e.g. "wit$datetime:datetime") or name (e.g. "wit$datetime").
+func HasEntity(name string) Predicate {
+	return func(msg *MessageResponse) bool {
+		return len(findEntities(msg, name)) > 0
+	}
+}
+
+// HasTrait matches messages where trait name has the given value,
+// e.g. HasTrait("wit$sentiment", "negative").
+func HasTrait(name, value string) Predicate {
+	return func(msg *MessageResponse) bool {
+		for _, t := range msg.Traits[name] {
+			if t.Value == value {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+type route struct {
+	handler    MessageHandler
+	threshold  *float64
+	predicates []Predicate
+}
+
+// Router - dispatches messages to handlers based on their top intent.
+type Router struct {
+	client     *Client
+	mu         sync.RWMutex
+	threshold  float64
+	routes     map[string]*route
+	fallback   MessageHandler
+	middleware []Middleware
+}
+
+// NewRouter returns a Router. The client is only used by ServeMessage and
+// may be nil if messages are parsed elsewhere.
+func NewRouter(client *Client) *Router {
+	return &Router{
+		client: client,
+		routes: make(map[string]*route),
+	}
+}
+
+// SetThreshold sets the minimum intent confidence for all routes
+// which don't define their own threshold.
+func (r *Router) SetThreshold(confidence float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.threshold = confidence
+}
+
+// Handle registers the handler for the given intent name.
+func (r *Router) Handle(intent string, h MessageHandler, opts ...RouteOption) {
+	rt := &route{handler: h}
+	for _, opt := range opts {
+		opt(rt)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes[intent] = rt
+}
+
+// HandleFunc registers the handler function for the given intent name.
+func (r *Router) HandleFunc(intent string, f func(ctx context.Context, msg *MessageResponse) error, opts ...RouteOption) {
+	r.Handle(intent, MessageHandlerFunc(f), opts...)
+}
+
+// Fallback registers the handler for messages without intent, with an
+// unknown intent, below the confidence threshold or failing a predicate.
+func (r *Router) Fallback(h MessageHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = h
+}
+
+// Use appends middleware. The first middleware added is the outermost one.
+func (r *Router) Use(mw ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = append(r.middleware, mw...)
+}
+
+// Dispatch routes msg to the matching handler.
+func (r *Router) Dispatch(ctx context.Context, msg *MessageResponse) error {
+	if msg == nil {
+		return errors.New("invalid message")
+	}
+
+	r.mu.RLock()
+	h := r.match(msg)
+	for i := len(r.middleware) - 1; i >= 0 && h != nil; i-- {
+		h = r.middleware[i](h)
+	}
+	r.mu.RUnlock()
+
+	if h == nil {
+		return ErrNoRoute
+	}
+
+	return h.HandleMessage(ctx, msg)
+}
+
+// ServeMessage parses text and dispatches the result. The parsed message
+// is returned along with the handler error.
+func (r *Router) ServeMessage(ctx context.Context, text string) (*MessageResponse, error) {
+	if r.client == nil {
+		return nil, errors.New("router has no client")
+	}
+
+	msg, err := r.client.ParseContext(ctx, &MessageRequest{Query: text})
+	if err != nil {
+		return nil, err
+	}
+
+	return msg, r.Dispatch(ctx, msg)
+}
+
+func (r *Router) match(msg *MessageResponse) MessageHandler {
+	intent, ok := topIntent(msg)
+	if !ok {
+		return r.fallback
+	}
+
+	rt, ok := r.routes[intent.Name]
+	if !ok {
+		return r.fallback
+	}
+
+	threshold := r.threshold
+	if rt.threshold != nil {
+		threshold = *rt.threshold
+	}
+	if intent.Confidence < threshold {
+		return r.fallback
+	}
+
+	for _, p := range rt.predicates {
+		if !p(msg) {
+			return r.fallback
+		}
+	}
+
+	return rt.handler
+}
+
+// topIntent returns the intent with the highest confidence.
+func topIntent(msg *MessageResponse) (MessageIntent, bool) {
+	if msg == nil || len(msg.Intents) == 0 {
+		return MessageIntent{}, false
+	}
+
+	top := msg.Intents[0]
+	for _, intent := range msg.Intents[1:] {
+		if intent.Confidence > top.Confidence {
+			top = intent
+		}
+	}
+
+	return top, true
+}
@@ -0,0 +1,155 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouterDispatch(t *testing.T) {
+	var got string
+	handler := func(name string) MessageHandlerFunc {
+		return func(ctx context.Context, msg *MessageResponse) error {
+			got = name
+			return nil
+		}
+	}
+
+	r := NewRouter(nil)
+	r.SetThreshold(0.5)
+	r.Handle("greet", handler("greet"))
+	r.Handle("order", handler("order"), WithThreshold(0.9))
+	r.Handle("complain", handler("complain"), WithPredicate(HasTrait("wit$sentiment", "negative")))
+	r.Handle("book", handler("book"), WithPredicate(HasEntity("wit$datetime")))
+	r.Fallback(handler("fallback"))
+
+	tests := []struct {
+		name string
+		msg  *MessageResponse
+		want string
+	}{
+		{
+			name: "top intent",
+			msg: &MessageResponse{Intents: []MessageIntent{
+				{Name: "order", Confidence: 0.3},
+				{Name: "greet", Confidence: 0.8},
+			}},
+			want: "greet",
+		},
+		{
+			name: "no intent",
+			msg:  &MessageResponse{},
+			want: "fallback",
+		},
+		{
+			name: "unknown intent",
+			msg:  &MessageResponse{Intents: []MessageIntent{{Name: "other", Confidence: 1}}},
+			want: "fallback",
+		},
+		{
+			name: "below global threshold",
+			msg:  &MessageResponse{Intents: []MessageIntent{{Name: "greet", Confidence: 0.4}}},
+			want: "fallback",
+		},
+		{
+			name: "below route threshold",
+			msg:  &MessageResponse{Intents: []MessageIntent{{Name: "order", Confidence: 0.8}}},
+			want: "fallback",
+		},
+		{
+			name: "trait predicate matches",
+			msg: &MessageResponse{
+				Intents: []MessageIntent{{Name: "complain", Confidence: 0.8}},
+				Traits:  map[string][]MessageTrait{"wit$sentiment": {{Value: "negative"}}},
+			},
+			want: "complain",
+		},
+		{
+			name: "trait predicate fails",
+			msg: &MessageResponse{
+				Intents: []MessageIntent{{Name: "complain", Confidence: 0.8}},
+				Traits:  map[string][]MessageTrait{"wit$sentiment": {{Value: "positive"}}},
+			},
+			want: "fallback",
+		},
+		{
+			name: "entity predicate matches",
+			msg: &MessageResponse{
+				Intents:  []MessageIntent{{Name: "book", Confidence: 0.8}},
+				Entities: map[string][]MessageEntity{"wit$datetime:datetime": {{Name: "wit$datetime"}}},
+			},
+			want: "book",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = ""
+			require.NoError(t, r.Dispatch(context.Background(), tt.msg))
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRouterNoRoute(t *testing.T) {
+	r := NewRouter(nil)
+	err := r.Dispatch(context.Background(), &MessageResponse{})
+	require.ErrorIs(t, err, ErrNoRoute)
+}
+
+func TestRouterMiddleware(t *testing.T) {
+	var calls []string
+	mw := func(name string) Middleware {
+		return func(next MessageHandler) MessageHandler {
+			return MessageHandlerFunc(func(ctx context.Context, msg *MessageResponse) error {
+				calls = append(calls, name)
+				return next.HandleMessage(ctx, msg)
+			})
+		}
+	}
+
+	r := NewRouter(nil)
+	r.Use(mw("first"), mw("second"))
+	r.HandleFunc("greet", func(ctx context.Context, msg *MessageResponse) error {
+		calls = append(calls, "handler")
+		return nil
+	})
+
+	err := r.Dispatch(context.Background(), &MessageResponse{Intents: []MessageIntent{{Name: "greet", Confidence: 1}}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"first", "second", "handler"}, calls)
+}
+
+func TestRouterServeMessage(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if q := req.URL.Query().Get("q"); q != "hi there" {
+			t.Errorf("unexpected query %v", q)
+		}
+		w.Write([]byte(`{
+			"msg_id": "msg1",
+			"text": "hi there",
+			"intents": [{"id": "i1", "name": "greet", "confidence": 0.9}]
+		}`))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	var handled bool
+	r := NewRouter(c)
+	r.HandleFunc("greet", func(ctx context.Context, msg *MessageResponse) error {
+		handled = true
+		return nil
+	})
+
+	msg, err := r.ServeMessage(context.Background(), "hi there")
+	require.NoError(t, err)
+	require.True(t, handled)
+	require.Equal(t, "msg1", msg.ID)
+}
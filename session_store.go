@@ -0,0 +1,125 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// SessionStore - persists dialog state per session.
+//
+// Load returns nil state and nil error when the session is unknown.
+type SessionStore interface {
+	Load(sessionID string) (*DialogState, error)
+	Save(sessionID string, state *DialogState) error
+	Delete(sessionID string) error
+}
+
+// MemorySessionStore - SessionStore keeping state in memory.
+type MemorySessionStore struct {
+	store *memoryStore[*DialogState]
+}
+
+// NewMemorySessionStore returns an empty in-memory store.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{store: newMemoryStore((*DialogState).clone)}
+}
+
+// Load returns a copy of the session state.
+func (s *MemorySessionStore) Load(sessionID string) (*DialogState, error) {
+	return s.store.load(sessionID), nil
+}
+
+// Save stores a copy of the session state.
+func (s *MemorySessionStore) Save(sessionID string, state *DialogState) error {
+	if state == nil {
+		return errors.New("invalid state")
+	}
+
+	s.store.save(sessionID, state)
+	return nil
+}
+
+// Delete removes the session state.
+func (s *MemorySessionStore) Delete(sessionID string) error {
+	s.store.delete(sessionID)
+	return nil
+}
+
+// FileSessionStore - SessionStore keeping one JSON file per session in Dir.
+type FileSessionStore struct {
+	Dir string
+}
+
+// NewFileSessionStore creates dir if needed and returns a store using it.
+func NewFileSessionStore(dir string) (*FileSessionStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &FileSessionStore{Dir: dir}, nil
+}
+
+// Load reads the session state from disk.
+func (s *FileSessionStore) Load(sessionID string) (*DialogState, error) {
+	return fileStore[*DialogState]{dir: s.Dir}.load(sessionID)
+}
+
+// Save atomically writes the session state to disk.
+func (s *FileSessionStore) Save(sessionID string, state *DialogState) error {
+	if state == nil {
+		return errors.New("invalid state")
+	}
+
+	return fileStore[*DialogState]{dir: s.Dir}.save(sessionID, state)
+}
+
+// Delete removes the session file.
+func (s *FileSessionStore) Delete(sessionID string) error {
+	return fileStore[*DialogState]{dir: s.Dir}.delete(sessionID)
+}
+
+func readJSONFile(path string, v interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	return json.NewDecoder(f).Decode(v)
+}
+
+// writeJSONFile writes v to a temporary file and renames it over path,
+// so readers never see a partially written file.
+func writeJSONFile(path string, v interface{}) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if err = json.NewEncoder(tmp).Encode(v); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	return commitTempFile(tmp, path)
+}
+
+// commitTempFile flushes and closes tmp, then renames it over path.
+func commitTempFile(tmp *os.File, path string) error {
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
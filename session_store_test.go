@@ -0,0 +1,35 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testSessionStore(t *testing.T, store SessionStore) {
+	testStore[*DialogState](t, store, func() *DialogState {
+		return &DialogState{
+			Intent: "book_flight",
+			Slots: map[string]MessageEntity{
+				"date": {Name: "wit$datetime", Body: "tomorrow", Start: 0, End: 8},
+			},
+			AwaitingConfirmation: true,
+		}
+	}, func(state *DialogState) {
+		state.Slots["date"] = MessageEntity{Body: "today"}
+	})
+
+	require.Error(t, store.Save("user/1", nil))
+}
+
+func TestMemorySessionStore(t *testing.T) {
+	testSessionStore(t, NewMemorySessionStore())
+}
+
+func TestFileSessionStore(t *testing.T) {
+	store, err := NewFileSessionStore(t.TempDir())
+	require.NoError(t, err)
+	testSessionStore(t, store)
+}
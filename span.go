@@ -0,0 +1,209 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Span - [Start, End) offsets of an entity in a text.
+//
+// Wit offsets count characters (Unicode code points), while Go strings are
+// indexed by bytes: use ByteRange or Text instead of slicing text directly.
+type Span struct {
+	Start int
+	End   int
+}
+
+// Span returns the offsets of the entity.
+func (e MessageEntity) Span() Span {
+	return Span{Start: e.Start, End: e.End}
+}
+
+// Span returns the offsets of the entity.
+func (e UtteranceEntity) Span() Span {
+	return Span{Start: e.Start, End: e.End}
+}
+
+// Span returns the offsets of the entity.
+func (e TrainingEntity) Span() Span {
+	return Span{Start: e.Start, End: e.End}
+}
+
+// Len - number of characters in the span.
+func (s Span) Len() int {
+	return s.End - s.Start
+}
+
+// Overlaps - whether both spans share at least one character.
+func (s Span) Overlaps(o Span) bool {
+	return s.Start < o.End && o.Start < s.End
+}
+
+// Contains - whether o is within s.
+func (s Span) Contains(o Span) bool {
+	return s.Start <= o.Start && o.End <= s.End
+}
+
+// ByteRange - converts the span into byte offsets of text.
+func (s Span) ByteRange(text string) (int, int, error) {
+	if s.Start < 0 || s.End < s.Start {
+		return 0, 0, fmt.Errorf("invalid span [%d, %d)", s.Start, s.End)
+	}
+
+	start, err := ByteOffset(text, s.Start)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := ByteOffset(text[start:], s.Len())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return start, start + end, nil
+}
+
+// Text - returns the part of text covered by the span.
+func (s Span) Text(text string) (string, error) {
+	start, end, err := s.ByteRange(text)
+	if err != nil {
+		return "", err
+	}
+	return text[start:end], nil
+}
+
+// CharCount - number of characters of text, as counted by Wit offsets.
+func CharCount(text string) int {
+	return utf8.RuneCountInString(text)
+}
+
+// ByteOffset - converts a character offset into a byte offset of text.
+func ByteOffset(text string, offset int) (int, error) {
+	if offset < 0 {
+		return 0, fmt.Errorf("offset %d out of range", offset)
+	}
+
+	n := 0
+	for i := range text {
+		if n == offset {
+			return i, nil
+		}
+		n++
+	}
+	if n == offset {
+		return len(text), nil
+	}
+
+	return 0, fmt.Errorf("offset %d out of range, text has %d characters", offset, n)
+}
+
+// CharOffset - converts a byte offset of text into a character offset.
+// The byte offset must be on a character boundary.
+func CharOffset(text string, offset int) (int, error) {
+	if offset < 0 || offset > len(text) {
+		return 0, fmt.Errorf("byte offset %d out of range", offset)
+	}
+	if offset < len(text) && !utf8.RuneStart(text[offset]) {
+		return 0, fmt.Errorf("byte offset %d is not on a character boundary", offset)
+	}
+
+	return utf8.RuneCountInString(text[:offset]), nil
+}
+
+// SpanFromByteRange - converts byte offsets of text, e.g. from the regexp or
+// strings packages, into a span.
+func SpanFromByteRange(text string, start, end int) (Span, error) {
+	s, err := CharOffset(text, start)
+	if err != nil {
+		return Span{}, err
+	}
+	if end < start {
+		return Span{}, fmt.Errorf("invalid byte range [%d, %d)", start, end)
+	}
+	e, err := CharOffset(text, end)
+	if err != nil {
+		return Span{}, err
+	}
+
+	return Span{Start: s, End: e}, nil
+}
+
+// OverlappingSpans - returns the index pairs of spans sharing characters,
+// including nested spans.
+func OverlappingSpans(spans []Span) [][2]int {
+	var pairs [][2]int
+	for i := range spans {
+		for j := i + 1; j < len(spans); j++ {
+			if spans[i].Overlaps(spans[j]) {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
+	}
+	return pairs
+}
+
+// Highlight - surrounds every span of text with open and close.
+//
+// Nested spans are supported, spans partially overlapping each other are not
+// as the markup would be ill-formed.
+func Highlight(text string, spans []Span, open, close string) (string, error) {
+	for _, p := range OverlappingSpans(spans) {
+		a, b := spans[p[0]], spans[p[1]]
+		if !a.Contains(b) && !b.Contains(a) {
+			return "", fmt.Errorf("spans [%d, %d) and [%d, %d) partially overlap", a.Start, a.End, b.Start, b.End)
+		}
+	}
+
+	type marker struct {
+		offset int
+		close  bool
+		length int
+	}
+	markers := make([]marker, 0, len(spans)*2)
+	for _, s := range spans {
+		start, end, err := s.ByteRange(text)
+		if err != nil {
+			return "", err
+		}
+		if start == end {
+			continue
+		}
+		markers = append(markers,
+			marker{offset: start, length: s.Len()},
+			marker{offset: end, close: true, length: s.Len()})
+	}
+
+	// At a given offset, spans are closed before others are opened; outer
+	// spans are opened first and closed last.
+	sort.SliceStable(markers, func(i, j int) bool {
+		a, b := markers[i], markers[j]
+		if a.offset != b.offset {
+			return a.offset < b.offset
+		}
+		if a.close != b.close {
+			return a.close
+		}
+		if a.close {
+			return a.length < b.length
+		}
+		return a.length > b.length
+	})
+
+	var b strings.Builder
+	last := 0
+	for _, m := range markers {
+		b.WriteString(text[last:m.offset])
+		last = m.offset
+		if m.close {
+			b.WriteString(close)
+		} else {
+			b.WriteString(open)
+		}
+	}
+	b.WriteString(text[last:])
+
+	return b.String(), nil
+}
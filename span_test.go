@@ -0,0 +1,84 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpanText(t *testing.T) {
+	text := "Café 🍕 in Zürich"
+
+	tests := []struct {
+		span Span
+		want string
+	}{
+		{Span{0, 4}, "Café"},
+		{Span{5, 6}, "🍕"},
+		{Span{10, 16}, "Zürich"},
+		{Span{16, 16}, ""},
+	}
+	for _, tt := range tests {
+		got, err := tt.span.Text(text)
+		require.NoError(t, err)
+		require.Equal(t, tt.want, got)
+	}
+
+	_, err := Span{10, 17}.Text(text)
+	require.Error(t, err)
+	_, err = Span{3, 2}.Text(text)
+	require.Error(t, err)
+}
+
+func TestByteAndCharOffsets(t *testing.T) {
+	text := "é🍕a"
+
+	for char, byteOffset := range []int{0, 2, 6, 7} {
+		got, err := ByteOffset(text, char)
+		require.NoError(t, err)
+		require.Equal(t, byteOffset, got)
+
+		back, err := CharOffset(text, byteOffset)
+		require.NoError(t, err)
+		require.Equal(t, char, back)
+	}
+
+	_, err := CharOffset(text, 1)
+	require.Error(t, err)
+	_, err = ByteOffset(text, 4)
+	require.Error(t, err)
+	require.Equal(t, 3, CharCount(text))
+
+	i := strings.Index(text, "a")
+	span, err := SpanFromByteRange(text, i, i+1)
+	require.NoError(t, err)
+	require.Equal(t, Span{2, 3}, span)
+}
+
+func TestOverlappingSpans(t *testing.T) {
+	spans := []Span{{0, 5}, {1, 3}, {4, 8}, {8, 10}}
+	require.Equal(t, [][2]int{{0, 1}, {0, 2}}, OverlappingSpans(spans))
+	require.True(t, spans[0].Contains(spans[1]))
+	require.False(t, spans[2].Overlaps(spans[3]))
+}
+
+func TestHighlight(t *testing.T) {
+	text := "Fly 🛫 to São Paulo"
+
+	got, err := Highlight(text, []Span{{13, 18}, {9, 18}, {4, 5}}, "[", "]")
+	require.NoError(t, err)
+	require.Equal(t, "Fly [🛫] to [São [Paulo]]", got)
+
+	_, err = Highlight(text, []Span{{0, 5}, {4, 8}}, "[", "]")
+	require.Error(t, err)
+}
+
+func TestEntitySpan(t *testing.T) {
+	msg := MessageEntity{Start: 1, End: 3}
+	require.Equal(t, Span{1, 3}, msg.Span())
+	require.Equal(t, Span{1, 3}, UtteranceEntity{Start: 1, End: 3}.Span())
+	require.Equal(t, Span{1, 3}, TrainingEntity{Start: 1, End: 3}.Span())
+}
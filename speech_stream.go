@@ -0,0 +1,124 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// SpeechEventType - kind of object streamed by /speech.
+type SpeechEventType string
+
+const (
+	// PartialTranscription - transcription of the audio received so far
+	PartialTranscription SpeechEventType = "PARTIAL_TRANSCRIPTION"
+	// FinalTranscription - transcription of the whole utterance
+	FinalTranscription SpeechEventType = "FINAL_TRANSCRIPTION"
+	// PartialUnderstanding - understanding of the partial transcription
+	PartialUnderstanding SpeechEventType = "PARTIAL_UNDERSTANDING"
+	// FinalUnderstanding - understanding of the final transcription
+	FinalUnderstanding SpeechEventType = "FINAL_UNDERSTANDING"
+)
+
+// SpeechEvent - one object streamed by /speech.
+type SpeechEvent struct {
+	Type SpeechEventType
+	Text string
+	// Speech is set for transcription events.
+	Speech *DictationSpeech
+	// Understanding is set for understanding events.
+	Understanding *MessageResponse
+}
+
+// IsFinal - whether the event is a final transcription or understanding.
+func (e *SpeechEvent) IsFinal() bool {
+	return e.Type == FinalTranscription || e.Type == FinalUnderstanding
+}
+
+// SpeechStream - events of a /speech request, see StreamSpeech.
+type SpeechStream struct {
+	stream *jsonStream
+}
+
+// StreamSpeech - sends audio for parsing and returns the stream of
+// transcription and understanding events, as they are produced.
+//
+// https://wit.ai/docs/http/#post__speech_link
+func (c *Client) StreamSpeech(ctx context.Context, req *MessageRequest) (*SpeechStream, error) {
+	if req == nil || req.Speech == nil {
+		return nil, errors.New("invalid request")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	q := buildParseQuery(req)
+
+	resp, err := c.streaming().requestContext(ctx, http.MethodPost, "/speech"+q, req.Speech.ContentType, req.Speech.File)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SpeechStream{stream: newJSONStream(resp)}, nil
+}
+
+// Next - returns the next event. It returns io.EOF after the last event.
+func (s *SpeechStream) Next() (*SpeechEvent, error) {
+	var raw json.RawMessage
+	if err := s.stream.next(&raw); err != nil {
+		return nil, err
+	}
+
+	return decodeSpeechEvent(raw)
+}
+
+// Close - stops reading events and releases the connection.
+func (s *SpeechStream) Close() error {
+	return s.stream.close()
+}
+
+func decodeSpeechEvent(raw json.RawMessage) (*SpeechEvent, error) {
+	var probe struct {
+		Type     SpeechEventType  `json:"type"`
+		IsFinal  *bool            `json:"is_final"`
+		ID       string           `json:"msg_id"`
+		Text     string           `json:"text"`
+		Speech   *DictationSpeech `json:"speech"`
+		Intents  json.RawMessage  `json:"intents"`
+		Entities json.RawMessage  `json:"entities"`
+		Traits   json.RawMessage  `json:"traits"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, err
+	}
+
+	event := &SpeechEvent{Type: probe.Type, Text: probe.Text, Speech: probe.Speech}
+
+	// Objects of older API versions have no type: understandings are the
+	// objects with a message ID, intents, entities or traits.
+	if event.Type == "" {
+		understanding := probe.ID != "" || probe.Intents != nil || probe.Entities != nil || probe.Traits != nil
+		final := probe.IsFinal == nil || *probe.IsFinal
+		switch {
+		case understanding && final:
+			event.Type = FinalUnderstanding
+		case understanding:
+			event.Type = PartialUnderstanding
+		case final:
+			event.Type = FinalTranscription
+		default:
+			event.Type = PartialTranscription
+		}
+	}
+
+	if event.Type == PartialUnderstanding || event.Type == FinalUnderstanding {
+		if err := json.Unmarshal(raw, &event.Understanding); err != nil {
+			return nil, err
+		}
+	}
+
+	return event, nil
+}
@@ -0,0 +1,147 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const speechStreamResponse = `{"text": "what", "type": "PARTIAL_TRANSCRIPTION", "is_final": false}
+{"text": "what is the weather", "type": "PARTIAL_UNDERSTANDING", "intents": [{"id": "i1", "name": "weather", "confidence": 0.7}]}
+{"text": "what is the weather", "type": "FINAL_TRANSCRIPTION", "is_final": true, "speech": {"confidence": 0.9, "tokens": [{"start": 0, "end": 300, "token": "what"}]}}
+{"text": "what is the weather", "type": "FINAL_UNDERSTANDING", "is_final": true, "intents": [{"id": "i1", "name": "weather", "confidence": 0.9}]}
+`
+
+func TestStreamSpeech(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if ct := req.Header.Get("Content-Type"); ct != "audio/wav" {
+			t.Errorf("unexpected content type %v", ct)
+		}
+		res.Write([]byte(speechStreamResponse))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+	stream, err := c.StreamSpeech(context.Background(), &MessageRequest{
+		Speech: &Speech{File: bytes.NewReader(nil), ContentType: "audio/wav"},
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var events []*SpeechEvent
+	for {
+		event, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		events = append(events, event)
+	}
+
+	require.Len(t, events, 4)
+	require.Equal(t, PartialTranscription, events[0].Type)
+	require.Equal(t, "what", events[0].Text)
+	require.Nil(t, events[0].Understanding)
+	require.Equal(t, PartialUnderstanding, events[1].Type)
+	require.Equal(t, 0.7, events[1].Understanding.Intents[0].Confidence)
+	require.Equal(t, FinalTranscription, events[2].Type)
+	require.Equal(t, "what", events[2].Speech.Tokens[0].Token)
+	require.True(t, events[3].IsFinal())
+	require.Equal(t, "weather", events[3].Understanding.Intents[0].Name)
+
+	_, err = stream.Next()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestStreamSpeechSlowResponse(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(`{"text": "what", "type": "PARTIAL_TRANSCRIPTION"}` + "\n"))
+		res.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		res.Write([]byte(`{"text": "what", "type": "FINAL_UNDERSTANDING", "is_final": true}`))
+	}))
+	defer testServer.Close()
+
+	// the stream outlives the client timeout
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+	c.SetHTTPClient(&http.Client{Timeout: 20 * time.Millisecond})
+	stream, err := c.StreamSpeech(context.Background(), &MessageRequest{
+		Speech: &Speech{File: bytes.NewReader(nil), ContentType: "audio/wav"},
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	_, err = stream.Next()
+	require.NoError(t, err)
+	event, err := stream.Next()
+	require.NoError(t, err)
+	require.True(t, event.IsFinal())
+}
+
+func TestSpeechReturnsFinalUnderstanding(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(speechStreamResponse))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+	msg, err := c.Speech(&MessageRequest{
+		Speech: &Speech{File: bytes.NewReader(nil), ContentType: "audio/wav"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0.9, msg.Intents[0].Confidence)
+}
+
+func TestSpeechTimeout(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(`{"text": "what", "type": "PARTIAL_TRANSCRIPTION"}` + "\n"))
+		res.(http.Flusher).Flush()
+		select {
+		case <-req.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer testServer.Close()
+
+	// Speech has no context, the client timeout still applies
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+	c.SetHTTPClient(&http.Client{Timeout: 20 * time.Millisecond})
+	start := time.Now()
+	_, err := c.Speech(&MessageRequest{
+		Speech: &Speech{File: bytes.NewReader(nil), ContentType: "audio/wav"},
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestStreamSpeechTruncated(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(`{"text": "what", "type": "PARTIAL_TRANSCRIPTION"} {"text": "wh`))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+	stream, err := c.StreamSpeech(context.Background(), &MessageRequest{
+		Speech: &Speech{File: bytes.NewReader(nil), ContentType: "audio/wav"},
+	})
+	require.NoError(t, err)
+
+	_, err = stream.Next()
+	require.NoError(t, err)
+	_, err = stream.Next()
+	require.Error(t, err)
+	require.NotEqual(t, io.EOF, err)
+}
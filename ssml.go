@@ -0,0 +1,389 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxSSMLBreak - longest pause of a break element.
+const MaxSSMLBreak = 10 * time.Second
+
+// BreakStrength - length of a pause relative to the speech.
+type BreakStrength string
+
+const (
+	// BreakNone - no pause
+	BreakNone BreakStrength = "none"
+	// BreakXWeak - shortest pause
+	BreakXWeak BreakStrength = "x-weak"
+	// BreakWeak - short pause
+	BreakWeak BreakStrength = "weak"
+	// BreakMedium - pause of a comma
+	BreakMedium BreakStrength = "medium"
+	// BreakStrong - pause of a sentence end
+	BreakStrong BreakStrength = "strong"
+	// BreakXStrong - pause of a paragraph end
+	BreakXStrong BreakStrength = "x-strong"
+)
+
+// Prosody - attributes of a prosody element. Empty fields are not set.
+//
+// Rate and Pitch are keywords (e.g. "slow", "x-high") or percentages within
+// the ranges of SynthesizeRequest (e.g. "150%"). Volume is a keyword (e.g.
+// "loud") or a change in decibels (e.g. "+6dB").
+type Prosody struct {
+	Rate   string
+	Pitch  string
+	Volume string
+}
+
+// SSMLBuilder - builds SSML documents for SynthesizeRequest.SSML.
+//
+// Text is escaped, and the document is validated by Render:
+//
+//	ssml, err := witai.NewSSML().
+//		Text("Your code is").
+//		Break(300 * time.Millisecond).
+//		SayAs("characters", "A1B2").
+//		Render()
+type SSMLBuilder struct {
+	buf strings.Builder
+}
+
+// NewSSML returns an empty SSML builder.
+func NewSSML() *SSMLBuilder {
+	return &SSMLBuilder{}
+}
+
+// Text - adds text to speak.
+func (b *SSMLBuilder) Text(text string) *SSMLBuilder {
+	b.escape(text)
+	return b
+}
+
+// Break - adds a pause of d, rounded to milliseconds.
+func (b *SSMLBuilder) Break(d time.Duration) *SSMLBuilder {
+	b.element("break", nil, "time", strconv.FormatInt(d.Milliseconds(), 10)+"ms")
+	return b
+}
+
+// BreakStrength - adds a pause of the given strength.
+func (b *SSMLBuilder) BreakStrength(strength BreakStrength) *SSMLBuilder {
+	b.element("break", nil, "strength", string(strength))
+	return b
+}
+
+// Prosody - adds the content written by fn with the given prosody.
+func (b *SSMLBuilder) Prosody(p Prosody, fn func(b *SSMLBuilder)) *SSMLBuilder {
+	b.element("prosody", fn, "rate", p.Rate, "pitch", p.Pitch, "volume", p.Volume)
+	return b
+}
+
+// SayAs - adds text read as interpretAs, e.g. "characters" or "date".
+func (b *SSMLBuilder) SayAs(interpretAs string, text string) *SSMLBuilder {
+	return b.SayAsFormat(interpretAs, "", text)
+}
+
+// SayAsFormat - same as SayAs with a format, e.g. "mdy" for dates.
+func (b *SSMLBuilder) SayAsFormat(interpretAs string, format string, text string) *SSMLBuilder {
+	b.element("say-as", textContent(text), "interpret-as", interpretAs, "format", format)
+	return b
+}
+
+// Phoneme - adds text pronounced as ph, in the "ipa" or "x-sampa" alphabet.
+func (b *SSMLBuilder) Phoneme(alphabet string, ph string, text string) *SSMLBuilder {
+	b.element("phoneme", textContent(text), "alphabet", alphabet, "ph", ph)
+	return b
+}
+
+// Audio - adds the audio file at src.
+func (b *SSMLBuilder) Audio(src string) *SSMLBuilder {
+	b.element("audio", nil, "src", src)
+	return b
+}
+
+// Voice - adds the content written by fn spoken by another voice.
+func (b *SSMLBuilder) Voice(name string, fn func(b *SSMLBuilder)) *SSMLBuilder {
+	b.element("voice", fn, "name", name)
+	return b
+}
+
+// Render - returns the validated document.
+func (b *SSMLBuilder) Render() (string, error) {
+	doc := "<speak>" + b.buf.String() + "</speak>"
+	if err := ValidateSSML(doc); err != nil {
+		return "", err
+	}
+	return doc, nil
+}
+
+func textContent(text string) func(b *SSMLBuilder) {
+	return func(b *SSMLBuilder) {
+		b.escape(text)
+	}
+}
+
+func (b *SSMLBuilder) escape(text string) {
+	// Writes to a strings.Builder don't fail.
+	_ = xml.EscapeText(&b.buf, []byte(text))
+}
+
+// element writes an element with the non empty attributes of attrs, given as
+// name and value pairs, and the content written by fn.
+func (b *SSMLBuilder) element(name string, fn func(b *SSMLBuilder), attrs ...string) {
+	b.buf.WriteString("<" + name)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if attrs[i+1] == "" {
+			continue
+		}
+		b.buf.WriteString(" " + attrs[i] + `="`)
+		b.escape(attrs[i+1])
+		b.buf.WriteString(`"`)
+	}
+
+	if fn == nil {
+		b.buf.WriteString("/>")
+		return
+	}
+	b.buf.WriteString(">")
+	fn(b)
+	b.buf.WriteString("</" + name + ">")
+}
+
+// ssmlText - allowed children key for text content.
+const ssmlText = "#text"
+
+// ssmlChildren lists the allowed children of each element.
+var ssmlChildren = map[string][]string{
+	"speak":   {ssmlText, "break", "prosody", "say-as", "phoneme", "audio", "voice"},
+	"voice":   {ssmlText, "break", "prosody", "say-as", "phoneme", "audio"},
+	"prosody": {ssmlText, "break", "prosody", "say-as", "phoneme", "audio"},
+	"say-as":  {ssmlText},
+	"phoneme": {ssmlText},
+	"audio":   {ssmlText},
+	"break":   {},
+}
+
+var (
+	prosodyRates   = []string{"x-slow", "slow", "medium", "fast", "x-fast", "default"}
+	prosodyPitches = []string{"x-low", "low", "medium", "high", "x-high", "default"}
+	prosodyVolumes = []string{"silent", "x-soft", "soft", "medium", "loud", "x-loud", "default"}
+	sayAsTypes     = []string{"characters", "spell-out", "cardinal", "number", "ordinal", "digits", "fraction", "unit", "date", "time", "telephone", "address", "interjection", "expletive"}
+	breakStrengths = []string{"none", "x-weak", "weak", "medium", "strong", "x-strong"}
+)
+
+// ValidateSSML - checks doc is a <speak> document using the SSML subset
+// supported by Wit, with valid nesting and attributes.
+func ValidateSSML(doc string) error {
+	d := xml.NewDecoder(strings.NewReader(doc))
+
+	var (
+		stack []string
+		root  bool
+	)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid SSML: %s", err.Error())
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			if len(stack) == 0 {
+				if root || name != "speak" {
+					return errors.New("invalid SSML: the document must have a single <speak> root")
+				}
+				root = true
+			} else if !allowedSSMLChild(stack[len(stack)-1], name) {
+				return fmt.Errorf("invalid SSML: <%s> not allowed in <%s>", name, stack[len(stack)-1])
+			}
+			if _, ok := ssmlChildren[name]; !ok {
+				return fmt.Errorf("invalid SSML: unsupported element <%s>", name)
+			}
+			if err := validateSSMLAttrs(name, t.Attr); err != nil {
+				return fmt.Errorf("invalid SSML: <%s>: %s", name, err.Error())
+			}
+			stack = append(stack, name)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if strings.TrimSpace(string(t)) == "" {
+				continue
+			}
+			if len(stack) == 0 {
+				return errors.New("invalid SSML: text outside of <speak>")
+			}
+			if !allowedSSMLChild(stack[len(stack)-1], ssmlText) {
+				return fmt.Errorf("invalid SSML: text not allowed in <%s>", stack[len(stack)-1])
+			}
+		case xml.ProcInst, xml.Comment:
+		default:
+			return fmt.Errorf("invalid SSML: unexpected %T", tok)
+		}
+	}
+
+	if !root {
+		return errors.New("invalid SSML: the document must have a single <speak> root")
+	}
+	return nil
+}
+
+func allowedSSMLChild(parent string, child string) bool {
+	return containsString(ssmlChildren[parent], child)
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func validateSSMLAttrs(element string, attrs []xml.Attr) error {
+	values := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		// Namespace declarations and xml:lang
+		if a.Name.Space != "" || a.Name.Local == "xmlns" {
+			continue
+		}
+		values[a.Name.Local] = a.Value
+	}
+
+	var allowed []string
+	switch element {
+	case "speak":
+		allowed = []string{"version"}
+	case "break":
+		allowed = []string{"time", "strength"}
+		if t, ok := values["time"]; ok {
+			if err := validateBreakTime(t); err != nil {
+				return err
+			}
+		}
+		if s, ok := values["strength"]; ok && !containsString(breakStrengths, s) {
+			return fmt.Errorf("unknown strength %q", s)
+		}
+	case "prosody":
+		allowed = []string{"rate", "pitch", "volume"}
+		if len(values) == 0 {
+			return errors.New("rate, pitch or volume is required")
+		}
+		if v, ok := values["rate"]; ok {
+			if err := validateProsodyValue("rate", v, prosodyRates, MinSynthesizeSpeed, MaxSynthesizeSpeed); err != nil {
+				return err
+			}
+		}
+		if v, ok := values["pitch"]; ok {
+			if err := validateProsodyValue("pitch", v, prosodyPitches, MinSynthesizePitch, MaxSynthesizePitch); err != nil {
+				return err
+			}
+		}
+		if v, ok := values["volume"]; ok {
+			if err := validateVolume(v); err != nil {
+				return err
+			}
+		}
+	case "say-as":
+		allowed = []string{"interpret-as", "format", "detail"}
+		if !containsString(sayAsTypes, values["interpret-as"]) {
+			return fmt.Errorf("unknown interpret-as %q", values["interpret-as"])
+		}
+	case "phoneme":
+		allowed = []string{"alphabet", "ph"}
+		if a := values["alphabet"]; a != "ipa" && a != "x-sampa" {
+			return fmt.Errorf("unknown alphabet %q", a)
+		}
+		if values["ph"] == "" {
+			return errors.New("ph is required")
+		}
+	case "audio":
+		allowed = []string{"src"}
+		u, err := url.Parse(values["src"])
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("src must be an http(s) URL, got %q", values["src"])
+		}
+	case "voice":
+		allowed = []string{"name"}
+		if values["name"] == "" {
+			return errors.New("name is required")
+		}
+	}
+
+	for name := range values {
+		if !containsString(allowed, name) {
+			return fmt.Errorf("unknown attribute %q", name)
+		}
+	}
+	return nil
+}
+
+func validateBreakTime(t string) error {
+	var (
+		d   time.Duration
+		err error
+	)
+	switch {
+	case strings.HasSuffix(t, "ms"):
+		var ms int
+		ms, err = strconv.Atoi(strings.TrimSuffix(t, "ms"))
+		d = time.Duration(ms) * time.Millisecond
+	case strings.HasSuffix(t, "s"):
+		var s float64
+		s, err = strconv.ParseFloat(strings.TrimSuffix(t, "s"), 64)
+		d = time.Duration(s * float64(time.Second))
+	default:
+		err = errors.New("missing unit")
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid time %q", t)
+	}
+	if d < 0 || d > MaxSSMLBreak {
+		return fmt.Errorf("time %q must be between 0 and %s", t, MaxSSMLBreak)
+	}
+	return nil
+}
+
+func validateProsodyValue(name, v string, keywords []string, min, max int) error {
+	if containsString(keywords, v) {
+		return nil
+	}
+
+	p, err := strconv.Atoi(strings.TrimSuffix(v, "%"))
+	if err != nil || !strings.HasSuffix(v, "%") {
+		return fmt.Errorf("invalid %s %q", name, v)
+	}
+	if p < min || p > max {
+		return fmt.Errorf("%s %q must be between %d%% and %d%%", name, v, min, max)
+	}
+	return nil
+}
+
+func validateVolume(v string) error {
+	if containsString(prosodyVolumes, v) {
+		return nil
+	}
+
+	db, err := strconv.ParseFloat(strings.TrimSuffix(v, "dB"), 64)
+	if err != nil || !strings.HasSuffix(v, "dB") || (v[0] != '+' && v[0] != '-') {
+		return fmt.Errorf("invalid volume %q", v)
+	}
+	if db < -40 || db > 40 {
+		return fmt.Errorf("volume %q must be between -40dB and +40dB", v)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSMLBuilder(t *testing.T) {
+	doc, err := NewSSML().
+		Text(`Tom & "Jerry" <3`).
+		Break(1500*time.Millisecond).
+		BreakStrength(BreakStrong).
+		Prosody(Prosody{Rate: "slow", Volume: "+6dB"}, func(b *SSMLBuilder) {
+			b.Text("Your code is ").SayAs("characters", "A1")
+		}).
+		SayAsFormat("date", "mdy", "10/19/2026").
+		Phoneme("ipa", "təˈmɑːtəʊ", "tomato").
+		Audio("https://example.com/beep.mp3?a=1&b=2").
+		Voice("Cooper", func(b *SSMLBuilder) {
+			b.Text("Bye")
+		}).
+		Render()
+	require.NoError(t, err)
+	require.Equal(t, `<speak>Tom &amp; &#34;Jerry&#34; &lt;3<break time="1500ms"/><break strength="strong"/>`+
+		`<prosody rate="slow" volume="+6dB">Your code is <say-as interpret-as="characters">A1</say-as></prosody>`+
+		`<say-as interpret-as="date" format="mdy">10/19/2026</say-as>`+
+		`<phoneme alphabet="ipa" ph="təˈmɑːtəʊ">tomato</phoneme>`+
+		`<audio src="https://example.com/beep.mp3?a=1&amp;b=2"/>`+
+		`<voice name="Cooper">Bye</voice></speak>`, doc)
+
+	_, err = NewSSML().Break(time.Minute).Render()
+	require.ErrorContains(t, err, "must be between 0 and 10s")
+
+	_, err = NewSSML().Prosody(Prosody{Pitch: "high"}, func(b *SSMLBuilder) {
+		b.Voice("Cooper", func(b *SSMLBuilder) { b.Text("hi") })
+	}).Render()
+	require.ErrorContains(t, err, "<voice> not allowed in <prosody>")
+}
+
+func TestValidateSSML(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		err  string
+	}{
+		{name: "valid", doc: `<?xml version="1.0"?><speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="en-US">Hi <break time="0.5s"/></speak>`},
+		{name: "prosody percentages", doc: `<speak><prosody rate="150%" pitch="80%" volume="-3dB">hi</prosody></speak>`},
+		{name: "no root", doc: `hello`, err: "text outside of <speak>"},
+		{name: "wrong root", doc: `<voice name="a">hi</voice>`, err: "single <speak> root"},
+		{name: "two roots", doc: `<speak>a</speak><speak>b</speak>`, err: "single <speak> root"},
+		{name: "malformed", doc: `<speak>a &b</speak>`, err: "invalid SSML"},
+		{name: "unclosed", doc: `<speak><prosody rate="slow">a</speak>`, err: "invalid SSML"},
+		{name: "unknown element", doc: `<speak><emphasis>a</emphasis></speak>`, err: "<emphasis> not allowed"},
+		{name: "nested say-as", doc: `<speak><say-as interpret-as="digits"><break/></say-as></speak>`, err: "<break> not allowed in <say-as>"},
+		{name: "text in break", doc: `<speak><break>a</break></speak>`, err: "text not allowed in <break>"},
+		{name: "unknown attribute", doc: `<speak><break duration="1s"/></speak>`, err: `unknown attribute "duration"`},
+		{name: "break unit", doc: `<speak><break time="5"/></speak>`, err: "invalid time"},
+		{name: "break strength", doc: `<speak><break strength="huge"/></speak>`, err: "unknown strength"},
+		{name: "empty prosody", doc: `<speak><prosody>a</prosody></speak>`, err: "rate, pitch or volume is required"},
+		{name: "rate range", doc: `<speak><prosody rate="500%">a</prosody></speak>`, err: "between 10% and 400%"},
+		{name: "pitch", doc: `<speak><prosody pitch="+2st">a</prosody></speak>`, err: "invalid pitch"},
+		{name: "volume", doc: `<speak><prosody volume="6dB">a</prosody></speak>`, err: "invalid volume"},
+		{name: "say-as type", doc: `<speak><say-as interpret-as="poem">a</say-as></speak>`, err: "unknown interpret-as"},
+		{name: "phoneme alphabet", doc: `<speak><phoneme alphabet="arpabet" ph="a">a</phoneme></speak>`, err: "unknown alphabet"},
+		{name: "audio src", doc: `<speak><audio src="file:///etc/passwd"/></speak>`, err: "http(s) URL"},
+		{name: "voice name", doc: `<speak><voice>a</voice></speak>`, err: "name is required"},
+		{name: "empty", doc: ``, err: "single <speak> root"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSSML(tt.doc)
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// memoryStore - values per session kept in memory, shared by the in-memory
+// SessionStore and ContextStore. Values are copied with clone on the way in
+// and out, so callers can't modify the stored ones.
+type memoryStore[T any] struct {
+	mu     sync.Mutex
+	values map[string]T
+	clone  func(T) T
+}
+
+func newMemoryStore[T any](clone func(T) T) *memoryStore[T] {
+	return &memoryStore[T]{values: make(map[string]T), clone: clone}
+}
+
+// load returns a copy of the session value, the zero value if unknown.
+func (s *memoryStore[T]) load(sessionID string) T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.values[sessionID]
+	if !ok {
+		var zero T
+		return zero
+	}
+
+	return s.clone(v)
+}
+
+func (s *memoryStore[T]) save(sessionID string, v T) {
+	v = s.clone(v)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[sessionID] = v
+}
+
+func (s *memoryStore[T]) delete(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, sessionID)
+}
+
+// fileStore - one JSON file per session in dir, shared by the on-disk
+// SessionStore and ContextStore.
+type fileStore[T any] struct {
+	dir string
+}
+
+// load reads the session value, the zero value if unknown.
+func (s fileStore[T]) load(sessionID string) (T, error) {
+	var v T
+	err := readJSONFile(s.path(sessionID), &v)
+	if errors.Is(err, os.ErrNotExist) {
+		var zero T
+		return zero, nil
+	}
+
+	return v, err
+}
+
+// save atomically writes the session value.
+func (s fileStore[T]) save(sessionID string, v T) error {
+	return writeJSONFile(s.path(sessionID), v)
+}
+
+func (s fileStore[T]) delete(sessionID string) error {
+	err := os.Remove(s.path(sessionID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (s fileStore[T]) path(sessionID string) string {
+	return filepath.Join(s.dir, url.PathEscape(sessionID)+".json")
+}
@@ -0,0 +1,40 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testedStore[T any] interface {
+	Load(sessionID string) (T, error)
+	Save(sessionID string, v T) error
+	Delete(sessionID string) error
+}
+
+// testStore checks a store with the values returned by newValue, which
+// mutate changes after they're saved.
+func testStore[T any](t *testing.T, store testedStore[T], newValue func() T, mutate func(T)) {
+	v, err := store.Load("user/1")
+	require.NoError(t, err)
+	require.Nil(t, v)
+
+	saved := newValue()
+	require.NoError(t, store.Save("user/1", saved))
+
+	// The store keeps its own copy.
+	mutate(saved)
+
+	v, err = store.Load("user/1")
+	require.NoError(t, err)
+	require.Equal(t, newValue(), v)
+
+	require.NoError(t, store.Delete("user/1"))
+	require.NoError(t, store.Delete("user/1"))
+
+	v, err = store.Load("user/1")
+	require.NoError(t, err)
+	require.Nil(t, v)
+}
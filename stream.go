@@ -0,0 +1,46 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// jsonStream - decodes the successive JSON objects of a streamed response.
+type jsonStream struct {
+	body    io.ReadCloser
+	decoder *json.Decoder
+	err     error
+}
+
+func newJSONStream(body io.ReadCloser) *jsonStream {
+	return &jsonStream{body: body, decoder: json.NewDecoder(body)}
+}
+
+// next decodes the next object into v. It returns io.EOF once the response
+// is complete; any other error is final and returned by all later calls.
+func (s *jsonStream) next(v interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	err := s.decoder.Decode(v)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = errors.New("unexpected end of stream")
+	}
+	if err != nil {
+		s.err = err
+		s.body.Close()
+	}
+
+	return err
+}
+
+func (s *jsonStream) close() error {
+	if s.err == nil {
+		s.err = errors.New("stream closed")
+	}
+	return s.body.Close()
+}
@@ -0,0 +1,213 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CaptionOptions - options for Captions. Zero fields use the defaults of
+// DefaultCaptionOptions.
+type CaptionOptions struct {
+	// MaxLineLength is the maximum number of characters per line. Longer
+	// words get a line of their own.
+	MaxLineLength int
+	// MaxLines is the maximum number of lines per cue.
+	MaxLines int
+	// MaxCueDuration is the maximum time a cue stays on screen.
+	MaxCueDuration time.Duration
+	// PauseThreshold is the silence between two words starting a new cue.
+	// A negative value disables splitting at pauses.
+	PauseThreshold time.Duration
+}
+
+// DefaultCaptionOptions - usual subtitling constraints.
+var DefaultCaptionOptions = CaptionOptions{
+	MaxLineLength:  42,
+	MaxLines:       2,
+	MaxCueDuration: 6 * time.Second,
+	PauseThreshold: 700 * time.Millisecond,
+}
+
+// Cue - caption displayed between Start and End.
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Lines []string
+}
+
+// WordTiming - position of a word in the audio, see WriteWordTimings.
+type WordTiming struct {
+	Word string `json:"word"`
+	// Start and End are in seconds.
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// Captions - groups the tokens of the transcriptions into cues.
+//
+// Token times of all responses must be relative to the same recording, as
+// returned by MergeDictation or the responses of a stream. Partial
+// transcriptions are ignored if there are final ones.
+func Captions(responses []*DictationResponse, opts *CaptionOptions) []Cue {
+	o := DefaultCaptionOptions
+	if opts != nil {
+		if opts.MaxLineLength > 0 {
+			o.MaxLineLength = opts.MaxLineLength
+		}
+		if opts.MaxLines > 0 {
+			o.MaxLines = opts.MaxLines
+		}
+		if opts.MaxCueDuration > 0 {
+			o.MaxCueDuration = opts.MaxCueDuration
+		}
+		if opts.PauseThreshold != 0 {
+			o.PauseThreshold = opts.PauseThreshold
+		}
+	}
+
+	var (
+		cues []Cue
+		cue  *Cue
+	)
+	for _, tok := range collectTokens(responses) {
+		start := time.Duration(tok.Start) * time.Millisecond
+		end := time.Duration(tok.End) * time.Millisecond
+
+		if cue != nil {
+			pause := o.PauseThreshold >= 0 && start-cue.End >= o.PauseThreshold
+			if pause || end-cue.Start > o.MaxCueDuration || !addWord(cue, tok.Token, o, false) {
+				cues = append(cues, *cue)
+				cue = nil
+			}
+		}
+		if cue == nil {
+			if len(cues) > 0 && start < cues[len(cues)-1].End {
+				start = cues[len(cues)-1].End
+			}
+			cue = &Cue{Start: start}
+			addWord(cue, tok.Token, o, true)
+		}
+		if end > cue.End {
+			cue.End = end
+		}
+	}
+	if cue != nil {
+		cues = append(cues, *cue)
+	}
+
+	return cues
+}
+
+// addWord adds word to the cue if it fits, or if force is set.
+func addWord(cue *Cue, word string, o CaptionOptions, force bool) bool {
+	if n := len(cue.Lines); n > 0 && CharCount(cue.Lines[n-1])+1+CharCount(word) <= o.MaxLineLength {
+		cue.Lines[n-1] += " " + word
+		return true
+	}
+	if len(cue.Lines) < o.MaxLines || force {
+		cue.Lines = append(cue.Lines, word)
+		return true
+	}
+	return false
+}
+
+// collectTokens returns the non empty tokens of the responses, sorted by
+// start time. Partial transcriptions are skipped if there are final ones,
+// as those repeat their words.
+func collectTokens(responses []*DictationResponse) []DictationToken {
+	finalOnly := false
+	for _, resp := range responses {
+		if resp != nil && resp.Final() {
+			finalOnly = true
+			break
+		}
+	}
+
+	var tokens []DictationToken
+	for _, resp := range responses {
+		if resp == nil || finalOnly && !resp.Final() {
+			continue
+		}
+		for _, tok := range resp.Speech.Tokens {
+			tok.Token = strings.TrimSpace(tok.Token)
+			if tok.Token != "" {
+				tokens = append(tokens, tok)
+			}
+		}
+	}
+
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[i].Start < tokens[j].Start
+	})
+	return tokens
+}
+
+// WriteSRT - writes cues in the SubRip format.
+func WriteSRT(w io.Writer, cues []Cue) error {
+	for i, cue := range cues {
+		_, err := fmt.Fprintf(w, "%d\n%s --> %s\n%s\n\n",
+			i+1, formatCueTime(cue.Start, ','), formatCueTime(cue.End, ','), strings.Join(cue.Lines, "\n"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var webVTTEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// WriteWebVTT - writes cues in the WebVTT format.
+func WriteWebVTT(w io.Writer, cues []Cue) error {
+	if _, err := io.WriteString(w, "WEBVTT\n\n"); err != nil {
+		return err
+	}
+
+	for _, cue := range cues {
+		lines := make([]string, len(cue.Lines))
+		for i, l := range cue.Lines {
+			lines[i] = webVTTEscaper.Replace(l)
+		}
+
+		_, err := fmt.Fprintf(w, "%s --> %s\n%s\n\n",
+			formatCueTime(cue.Start, '.'), formatCueTime(cue.End, '.'), strings.Join(lines, "\n"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WordTimings - returns the words of the transcriptions with their times,
+// see Captions.
+func WordTimings(responses []*DictationResponse) []WordTiming {
+	words := []WordTiming{}
+	for _, tok := range collectTokens(responses) {
+		words = append(words, WordTiming{
+			Word:  tok.Token,
+			Start: float64(tok.Start) / 1000,
+			End:   float64(tok.End) / 1000,
+		})
+	}
+	return words
+}
+
+// WriteWordTimings - writes the WordTimings of the transcriptions as a JSON
+// array.
+func WriteWordTimings(w io.Writer, responses []*DictationResponse) error {
+	return json.NewEncoder(w).Encode(WordTimings(responses))
+}
+
+// formatCueTime formats d as hh:mm:ss followed by sep and milliseconds.
+func formatCueTime(d time.Duration, sep byte) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
@@ -0,0 +1,104 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func captionResponses() []*DictationResponse {
+	return []*DictationResponse{
+		{Speech: DictationSpeech{Tokens: []DictationToken{
+			{Start: 0, End: 300, Token: "Hello"},
+			{Start: 300, End: 700, Token: "there,"},
+			{Start: 700, End: 1000, Token: "how"},
+			{Start: 1000, End: 1200, Token: "are"},
+			{Start: 1200, End: 1500, Token: "you?"},
+		}}},
+		{Speech: DictationSpeech{Tokens: []DictationToken{
+			{Start: 3000, End: 3400, Token: "Fine"},
+			{Start: 3400, End: 3900, Token: "<thanks>"},
+		}}},
+	}
+}
+
+func TestCaptions(t *testing.T) {
+	cues := Captions(captionResponses(), nil)
+	require.Equal(t, []Cue{
+		{Start: 0, End: 1500 * time.Millisecond, Lines: []string{"Hello there, how are you?"}},
+		{Start: 3 * time.Second, End: 3900 * time.Millisecond, Lines: []string{"Fine <thanks>"}},
+	}, cues)
+
+	cues = Captions(captionResponses(), &CaptionOptions{MaxLineLength: 12, MaxLines: 1, PauseThreshold: -1})
+	require.Equal(t, []Cue{
+		{Start: 0, End: 700 * time.Millisecond, Lines: []string{"Hello there,"}},
+		{Start: 700 * time.Millisecond, End: 1500 * time.Millisecond, Lines: []string{"how are you?"}},
+		{Start: 3 * time.Second, End: 3400 * time.Millisecond, Lines: []string{"Fine"}},
+		{Start: 3400 * time.Millisecond, End: 3900 * time.Millisecond, Lines: []string{"<thanks>"}},
+	}, cues)
+
+	cues = Captions(captionResponses(), &CaptionOptions{MaxCueDuration: time.Second})
+	require.Equal(t, []Cue{
+		{Start: 0, End: time.Second, Lines: []string{"Hello there, how"}},
+		{Start: time.Second, End: 1500 * time.Millisecond, Lines: []string{"are you?"}},
+		{Start: 3 * time.Second, End: 3900 * time.Millisecond, Lines: []string{"Fine <thanks>"}},
+	}, cues)
+}
+
+func TestCaptionsSkipPartials(t *testing.T) {
+	responses := []*DictationResponse{
+		{Type: "PARTIAL_TRANSCRIPTION", Speech: DictationSpeech{Tokens: []DictationToken{
+			{Start: 0, End: 300, Token: "Hello"},
+		}}},
+		{Type: "FINAL_TRANSCRIPTION", IsFinal: true, Speech: DictationSpeech{Tokens: []DictationToken{
+			{Start: 0, End: 300, Token: "Hello"},
+			{Start: 300, End: 700, Token: "world"},
+		}}},
+	}
+
+	cues := Captions(responses, nil)
+	require.Equal(t, []Cue{
+		{Start: 0, End: 700 * time.Millisecond, Lines: []string{"Hello world"}},
+	}, cues)
+	require.Len(t, WordTimings(responses), 2)
+}
+
+func TestWriteSRT(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteSRT(&buf, Captions(captionResponses(), nil)))
+	require.Equal(t, `1
+00:00:00,000 --> 00:00:01,500
+Hello there, how are you?
+
+2
+00:00:03,000 --> 00:00:03,900
+Fine <thanks>
+
+`, buf.String())
+}
+
+func TestWriteWebVTT(t *testing.T) {
+	var buf bytes.Buffer
+	cues := []Cue{{Start: time.Hour + 2*time.Minute + 3*time.Second + 4*time.Millisecond, End: time.Hour + 3*time.Minute, Lines: []string{"Fine <thanks>", "& you"}}}
+	require.NoError(t, WriteWebVTT(&buf, cues))
+	require.Equal(t, `WEBVTT
+
+01:02:03.004 --> 01:03:00.000
+Fine &lt;thanks&gt;
+&amp; you
+
+`, buf.String())
+}
+
+func TestWriteWordTimings(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteWordTimings(&buf, captionResponses()[1:]))
+	require.JSONEq(t, `[
+		{"word": "Fine", "start": 3, "end": 3.4},
+		{"word": "<thanks>", "start": 3.4, "end": 3.9}
+	]`, buf.String())
+}
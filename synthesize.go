@@ -0,0 +1,127 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// SynthesizeCodec - audio format returned by /synthesize.
+type SynthesizeCodec string
+
+const (
+	// CodecMP3 - MP3 audio
+	CodecMP3 SynthesizeCodec = "audio/mpeg"
+	// CodecPCM - headerless 16 bits little endian samples
+	CodecPCM SynthesizeCodec = "audio/pcm16"
+	// CodecWAV - WAV file
+	CodecWAV SynthesizeCodec = "audio/wav"
+)
+
+const (
+	// MinSynthesizeSpeed - slowest speed, in percent of the normal speed
+	MinSynthesizeSpeed = 10
+	// MaxSynthesizeSpeed - fastest speed, in percent of the normal speed
+	MaxSynthesizeSpeed = 400
+	// MinSynthesizePitch - lowest pitch, in percent of the voice pitch
+	MinSynthesizePitch = 25
+	// MaxSynthesizePitch - highest pitch, in percent of the voice pitch
+	MaxSynthesizePitch = 400
+)
+
+// SynthesizeRequest - text or SSML to speak.
+//
+// https://wit.ai/docs/http/#post__synthesize_link
+type SynthesizeRequest struct {
+	// Text and SSML are exclusive.
+	Text string
+	SSML string
+	// Voice is required, see GetVoices.
+	Voice string
+	Style string
+	// Speed and Pitch are percentages, 100 by default.
+	Speed int
+	Pitch int
+	// Codec defaults to CodecMP3.
+	Codec SynthesizeCodec
+}
+
+type synthesizeBody struct {
+	Q     string `json:"q"`
+	Voice string `json:"voice"`
+	Style string `json:"style,omitempty"`
+	Speed int    `json:"speed,omitempty"`
+	Pitch int    `json:"pitch,omitempty"`
+}
+
+// Validate - checks the request before sending it.
+func (req *SynthesizeRequest) Validate() error {
+	switch {
+	case req.Text == "" && req.SSML == "":
+		return errors.New("invalid synthesize request: text or SSML is required")
+	case req.Text != "" && req.SSML != "":
+		return errors.New("invalid synthesize request: text and SSML are exclusive")
+	case req.Voice == "":
+		return errors.New("invalid synthesize request: voice is required")
+	case req.Speed != 0 && (req.Speed < MinSynthesizeSpeed || req.Speed > MaxSynthesizeSpeed):
+		return fmt.Errorf("invalid synthesize request: speed must be between %d and %d", MinSynthesizeSpeed, MaxSynthesizeSpeed)
+	case req.Pitch != 0 && (req.Pitch < MinSynthesizePitch || req.Pitch > MaxSynthesizePitch):
+		return fmt.Errorf("invalid synthesize request: pitch must be between %d and %d", MinSynthesizePitch, MaxSynthesizePitch)
+	}
+
+	switch req.Codec {
+	case "", CodecMP3, CodecPCM, CodecWAV:
+	default:
+		return fmt.Errorf("invalid synthesize request: unknown codec %q", req.Codec)
+	}
+
+	if req.SSML != "" {
+		if err := ValidateSSML(req.SSML); err != nil {
+			return fmt.Errorf("invalid synthesize request: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Synthesize - returns the audio of the spoken text.
+//
+// The audio is streamed as it's produced: it must be closed, and only ctx
+// bounds the time spent reading it.
+func (c *Client) Synthesize(ctx context.Context, req SynthesizeRequest) (io.ReadCloser, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	codec := req.Codec
+	if codec == "" {
+		codec = CodecMP3
+	}
+
+	q := req.Text
+	if req.SSML != "" {
+		q = req.SSML
+	}
+
+	body, err := json.Marshal(synthesizeBody{
+		Q:     q,
+		Voice: req.Voice,
+		Style: req.Style,
+		Speed: req.Speed,
+		Pitch: req.Pitch,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The Accept header selects the codec, the version goes in the query.
+	u := "/synthesize?v=" + url.QueryEscape(c.Version)
+	return c.streaming().requestAccept(ctx, http.MethodPost, u, "application/json", string(codec), bytes.NewReader(body))
+}
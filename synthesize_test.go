@@ -0,0 +1,105 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSynthesize(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.URL.Path != "/synthesize" {
+			t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+		}
+		if v := req.URL.Query().Get("v"); v != DefaultVersion {
+			t.Errorf("unexpected version %v", v)
+		}
+		if accept := req.Header.Get("Accept"); accept != "audio/wav" {
+			t.Errorf("unexpected accept %v", accept)
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Errorf("unable to decode body: %v", err)
+		}
+		want := map[string]interface{}{"q": "<speak>hi</speak>", "voice": "Rebecca", "style": "soft", "speed": 150.0}
+		for k, v := range want {
+			if body[k] != v {
+				t.Errorf("unexpected %s: %v", k, body[k])
+			}
+		}
+		if _, ok := body["pitch"]; ok {
+			t.Errorf("unexpected pitch")
+		}
+
+		res.Header().Set("Content-Type", "audio/wav")
+		res.Write([]byte("RIFF....WAVE"))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	speech, err := c.Synthesize(context.Background(), SynthesizeRequest{
+		SSML:  "<speak>hi</speak>",
+		Voice: "Rebecca",
+		Style: "soft",
+		Speed: 150,
+		Codec: CodecWAV,
+	})
+	require.NoError(t, err)
+	defer speech.Close()
+
+	b, err := io.ReadAll(speech)
+	require.NoError(t, err)
+	require.Equal(t, "RIFF....WAVE", string(b))
+}
+
+func TestSynthesizeRequestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		req  SynthesizeRequest
+		err  string
+	}{
+		{name: "valid", req: SynthesizeRequest{Text: "hi", Voice: "Rebecca"}},
+		{name: "no text", req: SynthesizeRequest{Voice: "Rebecca"}, err: "text or SSML is required"},
+		{name: "text and SSML", req: SynthesizeRequest{Text: "hi", SSML: "<speak>hi</speak>", Voice: "Rebecca"}, err: "exclusive"},
+		{name: "no voice", req: SynthesizeRequest{Text: "hi"}, err: "voice is required"},
+		{name: "speed", req: SynthesizeRequest{Text: "hi", Voice: "Rebecca", Speed: 500}, err: "speed"},
+		{name: "pitch", req: SynthesizeRequest{Text: "hi", Voice: "Rebecca", Pitch: 10}, err: "pitch"},
+		{name: "codec", req: SynthesizeRequest{Text: "hi", Voice: "Rebecca", Codec: "audio/ogg"}, err: "unknown codec"},
+		{name: "SSML", req: SynthesizeRequest{SSML: "hi", Voice: "Rebecca"}, err: "<speak>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestSynthesizeError(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write([]byte(`{"error": "Unknown voice", "code": "bad-request"}`))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	_, err := c.Synthesize(context.Background(), SynthesizeRequest{Text: "hi", Voice: "Nobody"})
+	require.ErrorContains(t, err, "Unknown voice")
+}
@@ -0,0 +1,259 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wit-ai/wit-go/v2/audio"
+)
+
+// DefaultTranscribeSampleRate - sample rate of the audio sent by Transcribe.
+const DefaultTranscribeSampleRate = 16000
+
+// TranscribeOptions - options for Transcribe.
+type TranscribeOptions struct {
+	// VAD configures the segmentation, audio.DefaultVADOptions by default.
+	VAD *audio.VADOptions
+	// SampleRate of the audio sent to Wit, DefaultTranscribeSampleRate by
+	// default.
+	SampleRate int
+	// MaxChunkDuration is the maximum length of the chunks sent by
+	// TranscribeMP3, audio.DefaultChunkDuration by default. See VAD for
+	// Transcribe.
+	MaxChunkDuration time.Duration
+	// Concurrency is the maximum number of segments in flight,
+	// DefaultBatchConcurrency by default.
+	Concurrency int
+	// Progress, if set, is called after each transcribed segment with the
+	// number of transcribed segments and the end of the last one in the
+	// recording. Calls are never concurrent.
+	Progress func(done int, position time.Duration)
+}
+
+// DictationPart - transcription of a part of a recording, see MergeDictation.
+type DictationPart struct {
+	// Offset is the start of the part in the recording.
+	Offset   time.Duration
+	Response *DictationResponse
+}
+
+// Transcribe - transcribes a recording of any length.
+//
+// The recording is split into speech segments by a voice activity detection,
+// and the segments are sent to /dictation concurrently, subject to the client
+// rate limit (see SetRateLimit). Audio is read as segments are sent, so the
+// recording is never fully held in memory.
+//
+// The transcriptions are merged with MergeDictation: token times are relative
+// to the start of the recording. Transcribe stops at the first failing
+// segment.
+func (c *Client) Transcribe(ctx context.Context, src audio.Source, opts *TranscribeOptions) (*DictationResponse, error) {
+	if opts == nil {
+		opts = &TranscribeOptions{}
+	}
+	rate := opts.SampleRate
+	if rate <= 0 {
+		rate = DefaultTranscribeSampleRate
+	}
+
+	segmenter := audio.NewSegmenter(audio.Resample(audio.Downmix(src), rate), opts.VAD)
+
+	return c.transcribeChunks(ctx, opts, func() (*audioChunk, error) {
+		seg, err := segmenter.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		body, contentType, err := encodeSegment(seg)
+		if err != nil {
+			return nil, err
+		}
+		return &audioChunk{start: seg.Start, end: seg.End, body: body, contentType: contentType}, nil
+	})
+}
+
+// TranscribeMP3 - same as Transcribe for an MP3 recording, which is split on
+// frame boundaries into chunks of at most opts.MaxChunkDuration and sent
+// without re-encoding.
+func (c *Client) TranscribeMP3(ctx context.Context, r io.Reader, opts *TranscribeOptions) (*DictationResponse, error) {
+	if opts == nil {
+		opts = &TranscribeOptions{}
+	}
+
+	splitter := audio.NewMP3Splitter(r, opts.MaxChunkDuration)
+
+	return c.transcribeChunks(ctx, opts, func() (*audioChunk, error) {
+		chunk, err := splitter.Next()
+		if err != nil {
+			return nil, err
+		}
+		return &audioChunk{
+			start:       chunk.Offset,
+			end:         chunk.Offset + chunk.Duration,
+			body:        chunk.Data,
+			contentType: string(AudioMP3),
+		}, nil
+	})
+}
+
+// audioChunk - part of a recording sent by transcribeChunks.
+type audioChunk struct {
+	start, end  time.Duration
+	body        []byte
+	contentType string
+}
+
+// transcribeChunks sends the chunks returned by next, until io.EOF, to
+// /dictation concurrently and merges the results.
+func (c *Client) transcribeChunks(ctx context.Context, opts *TranscribeOptions, next func() (*audioChunk, error)) (*DictationResponse, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		parts    []DictationPart
+		firstErr error
+		sem      = make(chan struct{}, concurrency)
+	)
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+		mu.Unlock()
+	}
+
+	for i := 0; ; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		chunk, err := next()
+		if err != nil {
+			<-sem
+			if err != io.EOF {
+				fail(fmt.Errorf("unable to read audio: %w", err))
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, chunk *audioChunk) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			req := DictationRequest{File: bytes.NewReader(chunk.body), ContentType: chunk.contentType}
+			resp, err := c.dictationContext(ctx, req)
+			if err != nil {
+				fail(fmt.Errorf("segment %d at %s: %w", i, chunk.start, err))
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			parts = append(parts, DictationPart{Offset: chunk.start, Response: resp})
+			if opts.Progress != nil {
+				opts.Progress(len(parts), chunk.end)
+			}
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return MergeDictation(parts), nil
+}
+
+func encodeSegment(seg *audio.Segment) ([]byte, string, error) {
+	pcm, err := audio.NewPCMReader(seg.Source(), 16)
+	if err != nil {
+		return nil, "", err
+	}
+
+	body, err := io.ReadAll(pcm)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, pcm.ContentType(), nil
+}
+
+// MergeDictation - merges the transcriptions of the parts of a recording into
+// a single final transcription.
+//
+// Parts are ordered by offset, and token times, in milliseconds, are shifted
+// by the offset of their part. The confidence is the average of the parts,
+// weighted by their number of tokens.
+func MergeDictation(parts []DictationPart) *DictationResponse {
+	sorted := make([]DictationPart, 0, len(parts))
+	for _, p := range parts {
+		if p.Response != nil {
+			sorted = append(sorted, p)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Offset < sorted[j].Offset
+	})
+
+	merged := &DictationResponse{
+		Type:    string(FinalTranscription),
+		IsFinal: true,
+		Speech:  DictationSpeech{Tokens: []DictationToken{}},
+	}
+
+	var (
+		texts    []string
+		weighted float64
+		weights  int
+	)
+	for _, p := range sorted {
+		resp := p.Response
+		if text := strings.TrimSpace(resp.Text); text != "" {
+			texts = append(texts, text)
+		}
+
+		offset := int(p.Offset.Milliseconds())
+		for _, tok := range resp.Speech.Tokens {
+			tok.Start += offset
+			tok.End += offset
+			merged.Speech.Tokens = append(merged.Speech.Tokens, tok)
+		}
+
+		weight := len(resp.Speech.Tokens)
+		if weight == 0 {
+			weight = 1
+		}
+		weighted += resp.Speech.Confidence * float64(weight)
+		weights += weight
+	}
+
+	merged.Text = strings.Join(texts, " ")
+	if weights > 0 {
+		merged.Speech.Confidence = weighted / float64(weights)
+	}
+
+	return merged
+}
@@ -0,0 +1,161 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wit-ai/wit-go/v2/audio"
+)
+
+// toneSource - mono Source alternating silence and tones, durations in
+// milliseconds at 16kHz.
+type toneSource struct {
+	samples []float64
+}
+
+func newToneSource(durations ...int) *toneSource {
+	s := &toneSource{}
+	for i, d := range durations {
+		for j := 0; j < d*16; j++ {
+			v := 0.0
+			if i%2 == 1 {
+				v = 0.5 * math.Sin(2*math.Pi*440*float64(j)/16000)
+			}
+			s.samples = append(s.samples, v)
+		}
+	}
+	return s
+}
+
+func (s *toneSource) Format() audio.Format {
+	return audio.Format{SampleRate: 16000, Channels: 1}
+}
+
+func (s *toneSource) ReadSamples(buf []float64) (int, error) {
+	if len(s.samples) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(buf, s.samples)
+	s.samples = s.samples[n:]
+	return n, nil
+}
+
+func TestTranscribe(t *testing.T) {
+	var requests int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if ct := req.Header.Get("Content-Type"); ct != "audio/raw;encoding=signed-integer;bits=16;rate=16000;endian=little" {
+			t.Errorf("unexpected content type %v", ct)
+		}
+		body, _ := io.ReadAll(req.Body)
+		// Tell segments apart by their length.
+		word := "short"
+		if len(body) > 2*16000*2 {
+			word = "long"
+		}
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprintf(res, `{"text": "%s", "type": "PARTIAL_TRANSCRIPTION", "speech": {"confidence": 0.1, "tokens": []}}
+{"text": "%s", "type": "FINAL_TRANSCRIPTION", "is_final": true, "speech": {"confidence": 0.9, "tokens": [{"start": 100, "end": 500, "token": "%s"}]}}`, word, word, word)
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	var progress int
+	resp, err := c.Transcribe(context.Background(), newToneSource(1000, 3000, 1000, 1000, 500), &TranscribeOptions{
+		Concurrency: 2,
+		Progress: func(done int, position time.Duration) {
+			progress = done
+		},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, requests)
+	require.Equal(t, 2, progress)
+	require.True(t, resp.Final())
+	require.Equal(t, "long short", resp.Text)
+	require.Equal(t, 0.9, resp.Speech.Confidence)
+	require.Equal(t, []DictationToken{
+		{Start: 910, End: 1310, Token: "long"},
+		{Start: 4900, End: 5300, Token: "short"},
+	}, resp.Speech.Tokens)
+}
+
+func TestTranscribeError(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write([]byte(`{"error": "bad audio", "code": "bad-request"}`))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	_, err := c.Transcribe(context.Background(), newToneSource(0, 1000), nil)
+	require.ErrorContains(t, err, "segment 0")
+}
+
+func TestMergeDictation(t *testing.T) {
+	merged := MergeDictation([]DictationPart{
+		{Offset: 2 * time.Second, Response: &DictationResponse{
+			Text:   "world",
+			Speech: DictationSpeech{Confidence: 0.5, Tokens: []DictationToken{{Start: 0, End: 300, Token: "world"}}},
+		}},
+		{Offset: 0, Response: &DictationResponse{
+			Text: "hello there",
+			Speech: DictationSpeech{Confidence: 0.8, Tokens: []DictationToken{
+				{Start: 0, End: 200, Token: "hello"},
+				{Start: 200, End: 500, Token: "there"},
+			}},
+		}},
+		{Offset: time.Second},
+	})
+
+	require.Equal(t, "hello there world", merged.Text)
+	require.InDelta(t, 0.7, merged.Speech.Confidence, 1e-9)
+	require.Equal(t, []DictationToken{
+		{Start: 0, End: 200, Token: "hello"},
+		{Start: 200, End: 500, Token: "there"},
+		{Start: 2000, End: 2300, Token: "world"},
+	}, merged.Speech.Tokens)
+}
+
+func TestTranscribeMP3(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if ct := req.Header.Get("Content-Type"); ct != "audio/mpeg3" {
+			t.Errorf("unexpected content type %v", ct)
+		}
+		body, _ := io.ReadAll(req.Body)
+		if len(body) == 0 || body[0] != 0xFF {
+			t.Errorf("chunk doesn't start with a frame")
+		}
+		res.Write([]byte(`{"text": "hi", "type": "FINAL_TRANSCRIPTION", "speech": {"confidence": 0.8, "tokens": [{"start": 0, "end": 200, "token": "hi"}]}}`))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	f, err := os.Open("testdata/test.mp3")
+	require.NoError(t, err)
+	defer f.Close()
+
+	resp, err := c.TranscribeMP3(context.Background(), f, &TranscribeOptions{MaxChunkDuration: 2 * time.Second})
+	require.NoError(t, err)
+	require.Equal(t, "hi hi", resp.Text)
+	require.Equal(t, []DictationToken{
+		{Start: 0, End: 200, Token: "hi"},
+		{Start: 1992, End: 2192, Token: "hi"},
+	}, resp.Speech.Tokens)
+}
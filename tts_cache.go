@@ -0,0 +1,330 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ttsCacheExtensions = map[SynthesizeCodec]string{
+	CodecMP3: ".mp3",
+	CodecPCM: ".pcm",
+	CodecWAV: ".wav",
+}
+
+// TTSCache - on-disk cache of synthesized audio.
+//
+// Files are named after TTSCacheKey, so the cache survives restarts and can
+// be shared by processes. The least recently used files are removed when
+// the cache grows over its maximum size.
+type TTSCache struct {
+	client  *Client
+	dir     string
+	maxSize int64
+
+	mu      sync.Mutex
+	lru     *list.List // of *ttsCacheEntry, most recent first
+	entries map[string]*list.Element
+	size    int64
+}
+
+type ttsCacheEntry struct {
+	name string
+	size int64
+}
+
+// NewTTSCache returns a cache of the audio synthesized by c, stored in dir.
+// maxSize is the maximum size of the cache in bytes, 0 for no limit.
+//
+// Files already in dir are reused, in the order of their modification time.
+func NewTTSCache(c *Client, dir string, maxSize int64) (*TTSCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	tc := &TTSCache{
+		client:  c,
+		dir:     dir,
+		maxSize: maxSize,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type cached struct {
+		entry   *ttsCacheEntry
+		modTime time.Time
+	}
+	var existing []cached
+	for _, f := range files {
+		// Left over by interrupted writes.
+		if strings.HasPrefix(f.Name(), ".tmp-") {
+			os.Remove(filepath.Join(dir, f.Name()))
+			continue
+		}
+		if !isTTSCacheFile(f.Name()) {
+			continue
+		}
+
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		existing = append(existing, cached{entry: &ttsCacheEntry{name: f.Name(), size: info.Size()}, modTime: info.ModTime()})
+	}
+
+	sort.Slice(existing, func(i, j int) bool {
+		return existing[i].modTime.Before(existing[j].modTime)
+	})
+	for _, e := range existing {
+		tc.entries[e.entry.name] = tc.lru.PushFront(e.entry)
+		tc.size += e.entry.size
+	}
+	tc.evict()
+
+	return tc, nil
+}
+
+func isTTSCacheFile(name string) bool {
+	for _, ext := range ttsCacheExtensions {
+		key := strings.TrimSuffix(name, ext)
+		if key != name && len(key) == sha256.Size*2 {
+			_, err := hex.DecodeString(key)
+			return err == nil
+		}
+	}
+	return false
+}
+
+// TTSCacheKey - returns the hash identifying the audio of req. Requests
+// only differing by default values have the same key.
+func TTSCacheKey(req SynthesizeRequest) string {
+	if req.Speed == 0 {
+		req.Speed = 100
+	}
+	if req.Pitch == 0 {
+		req.Pitch = 100
+	}
+	if req.Codec == "" {
+		req.Codec = CodecMP3
+	}
+
+	// Marshalling a struct of strings and integers doesn't fail.
+	b, _ := json.Marshal(struct {
+		Text  string          `json:"text"`
+		SSML  string          `json:"ssml"`
+		Voice string          `json:"voice"`
+		Style string          `json:"style"`
+		Speed int             `json:"speed"`
+		Pitch int             `json:"pitch"`
+		Codec SynthesizeCodec `json:"codec"`
+	}{req.Text, req.SSML, req.Voice, req.Style, req.Speed, req.Pitch, req.Codec})
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func ttsCacheFileName(req SynthesizeRequest) string {
+	codec := req.Codec
+	if codec == "" {
+		codec = CodecMP3
+	}
+	return TTSCacheKey(req) + ttsCacheExtensions[codec]
+}
+
+// Synthesize - same as Client.Synthesize, returning the cached audio if any.
+//
+// On a miss, the audio is streamed to the caller while being written to the
+// cache. It's only added to the cache once fully read and closed.
+func (tc *TTSCache) Synthesize(ctx context.Context, req SynthesizeRequest) (io.ReadCloser, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	name := ttsCacheFileName(req)
+	if f := tc.open(name); f != nil {
+		return f, nil
+	}
+
+	audio, err := tc.client.Synthesize(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(tc.dir, ".tmp-*")
+	if err != nil {
+		audio.Close()
+		return nil, err
+	}
+
+	return &ttsCacheWriter{cache: tc, name: name, audio: audio, tmp: tmp}, nil
+}
+
+// Contains - whether the audio of req is cached.
+func (tc *TTSCache) Contains(req SynthesizeRequest) bool {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	_, ok := tc.entries[ttsCacheFileName(req)]
+	return ok
+}
+
+// Size - total size of the cached files, in bytes.
+func (tc *TTSCache) Size() int64 {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	return tc.size
+}
+
+// Len - number of cached files.
+func (tc *TTSCache) Len() int {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	return tc.lru.Len()
+}
+
+// Prewarm - synthesizes the requests that aren't cached yet, using a bounded
+// pool of workers. All requests are tried, the errors of the failing ones
+// are joined in the returned error.
+func (tc *TTSCache) Prewarm(ctx context.Context, reqs []SynthesizeRequest, opts *BatchOptions) error {
+	errs := make([]error, len(reqs))
+	runBatch(ctx, len(reqs), opts, func(i int) {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			return
+		}
+		if tc.Contains(reqs[i]) {
+			return
+		}
+
+		audio, err := tc.Synthesize(ctx, reqs[i])
+		if err == nil {
+			_, err = io.Copy(io.Discard, audio)
+			if closeErr := audio.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			errs[i] = fmt.Errorf("request %d: %w", i, err)
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// open opens the cached file name, or returns nil if it isn't cached.
+func (tc *TTSCache) open(name string) *os.File {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	elem, ok := tc.entries[name]
+	if !ok {
+		return nil
+	}
+
+	path := filepath.Join(tc.dir, name)
+	f, err := os.Open(path)
+	if err != nil {
+		// Removed by another process.
+		tc.remove(elem)
+		return nil
+	}
+
+	tc.lru.MoveToFront(elem)
+	// Keeps the order across restarts.
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return f
+}
+
+// add records a new file and evicts the oldest ones if needed.
+func (tc *TTSCache) add(name string, size int64) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if elem, ok := tc.entries[name]; ok {
+		tc.size -= elem.Value.(*ttsCacheEntry).size
+		elem.Value.(*ttsCacheEntry).size = size
+		tc.lru.MoveToFront(elem)
+	} else {
+		tc.entries[name] = tc.lru.PushFront(&ttsCacheEntry{name: name, size: size})
+	}
+	tc.size += size
+	tc.evict()
+}
+
+func (tc *TTSCache) evict() {
+	for tc.maxSize > 0 && tc.size > tc.maxSize && tc.lru.Len() > 0 {
+		elem := tc.lru.Back()
+		os.Remove(filepath.Join(tc.dir, elem.Value.(*ttsCacheEntry).name))
+		tc.remove(elem)
+	}
+}
+
+func (tc *TTSCache) remove(elem *list.Element) {
+	entry := elem.Value.(*ttsCacheEntry)
+	tc.lru.Remove(elem)
+	delete(tc.entries, entry.name)
+	tc.size -= entry.size
+}
+
+// ttsCacheWriter - synthesized audio written to the cache as it's read.
+type ttsCacheWriter struct {
+	cache    *TTSCache
+	name     string
+	audio    io.ReadCloser
+	tmp      *os.File
+	size     int64
+	complete bool
+	err      error
+}
+
+func (w *ttsCacheWriter) Read(p []byte) (int, error) {
+	n, err := w.audio.Read(p)
+	if n > 0 && w.err == nil {
+		_, w.err = w.tmp.Write(p[:n])
+		w.size += int64(n)
+	}
+	if err == io.EOF {
+		w.complete = true
+	}
+	return n, err
+}
+
+// Close - releases the response, and adds the audio to the cache if it was
+// fully read.
+func (w *ttsCacheWriter) Close() error {
+	err := w.audio.Close()
+	defer os.Remove(w.tmp.Name())
+
+	if !w.complete || w.err != nil {
+		w.tmp.Close()
+		return err
+	}
+
+	if commitErr := commitTempFile(w.tmp, filepath.Join(w.cache.dir, w.name)); commitErr != nil {
+		return commitErr
+	}
+	w.cache.add(w.name, w.size)
+
+	return err
+}
@@ -0,0 +1,131 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTTSServer returns a server answering 10 bytes of audio per request, or
+// an error for the text "fail".
+func newTTSServer(t *testing.T, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(requests, 1)
+
+		var body synthesizeBody
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Errorf("unable to decode body: %v", err)
+		}
+		if body.Q == "fail" {
+			res.WriteHeader(http.StatusBadRequest)
+			res.Write([]byte(`{"error": "failed"}`))
+			return
+		}
+		res.Write([]byte(strings.Repeat(body.Q[:1], 10)))
+	}))
+}
+
+func readCached(t *testing.T, tc *TTSCache, text string) string {
+	t.Helper()
+	audio, err := tc.Synthesize(context.Background(), SynthesizeRequest{Text: text, Voice: "Rebecca"})
+	require.NoError(t, err)
+	b, err := io.ReadAll(audio)
+	require.NoError(t, err)
+	require.NoError(t, audio.Close())
+	return string(b)
+}
+
+func TestTTSCacheKey(t *testing.T) {
+	req := SynthesizeRequest{Text: "hi", Voice: "Rebecca"}
+	require.Len(t, TTSCacheKey(req), 64)
+	require.Equal(t, TTSCacheKey(req), TTSCacheKey(SynthesizeRequest{Text: "hi", Voice: "Rebecca", Speed: 100, Codec: CodecMP3}))
+	require.NotEqual(t, TTSCacheKey(req), TTSCacheKey(SynthesizeRequest{Text: "hi", Voice: "Rebecca", Style: "soft"}))
+	require.NotEqual(t, TTSCacheKey(req), TTSCacheKey(SynthesizeRequest{Text: "hi", Voice: "Rebecca", Codec: CodecWAV}))
+	require.NotEqual(t, TTSCacheKey(req), TTSCacheKey(SynthesizeRequest{SSML: "hi", Voice: "Rebecca"}))
+}
+
+func TestTTSCache(t *testing.T) {
+	var requests int32
+	testServer := newTTSServer(t, &requests)
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	dir := t.TempDir()
+	tc, err := NewTTSCache(c, dir, 25)
+	require.NoError(t, err)
+
+	require.Equal(t, "aaaaaaaaaa", readCached(t, tc, "a"))
+	require.Equal(t, "aaaaaaaaaa", readCached(t, tc, "a"))
+	require.EqualValues(t, 1, requests)
+	require.Equal(t, 1, tc.Len())
+	require.EqualValues(t, 10, tc.Size())
+
+	_, err = os.Stat(filepath.Join(dir, TTSCacheKey(SynthesizeRequest{Text: "a", Voice: "Rebecca"})+".mp3"))
+	require.NoError(t, err)
+
+	// Partially read audio isn't cached.
+	audio, err := tc.Synthesize(context.Background(), SynthesizeRequest{Text: "b", Voice: "Rebecca"})
+	require.NoError(t, err)
+	_, err = audio.Read(make([]byte, 2))
+	require.NoError(t, err)
+	require.NoError(t, audio.Close())
+	require.Equal(t, 1, tc.Len())
+
+	// b then c evict a, the least recently used.
+	readCached(t, tc, "b")
+	readCached(t, tc, "c")
+	require.Equal(t, 2, tc.Len())
+	require.False(t, tc.Contains(SynthesizeRequest{Text: "a", Voice: "Rebecca"}))
+	require.True(t, tc.Contains(SynthesizeRequest{Text: "b", Voice: "Rebecca"}))
+
+	// Cached files are reused by new caches, temporary files are removed.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".tmp-123"), []byte("x"), 0o644))
+	tc, err = NewTTSCache(c, dir, 25)
+	require.NoError(t, err)
+	require.Equal(t, 2, tc.Len())
+	require.EqualValues(t, 20, tc.Size())
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	requests = 0
+	require.Equal(t, "cccccccccc", readCached(t, tc, "c"))
+	require.EqualValues(t, 0, requests)
+}
+
+func TestTTSCachePrewarm(t *testing.T) {
+	var requests int32
+	testServer := newTTSServer(t, &requests)
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	tc, err := NewTTSCache(c, t.TempDir(), 0)
+	require.NoError(t, err)
+	readCached(t, tc, "a")
+
+	err = tc.Prewarm(context.Background(), []SynthesizeRequest{
+		{Text: "a", Voice: "Rebecca"},
+		{Text: "b", Voice: "Rebecca"},
+		{Text: "fail", Voice: "Rebecca"},
+		{Text: "c", Voice: "Rebecca"},
+	}, &BatchOptions{Concurrency: 2})
+	require.ErrorContains(t, err, "request 2")
+	require.EqualValues(t, 4, requests)
+	require.Equal(t, 3, tc.Len())
+}
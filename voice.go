@@ -0,0 +1,168 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// Gender - gender of a voice.
+type Gender string
+
+const (
+	// GenderFemale - female voice
+	GenderFemale Gender = "female"
+	// GenderMale - male voice
+	GenderMale Gender = "male"
+)
+
+// ErrUnknownVoice is returned when a voice isn't in the catalog.
+var ErrUnknownVoice = errors.New("unknown voice")
+
+// ErrUnsupportedStyle is returned when a voice doesn't support a style.
+var ErrUnsupportedStyle = errors.New("unsupported voice style")
+
+// Voice - text-to-speech voice.
+//
+// https://wit.ai/docs/http/#get__voices_link
+type Voice struct {
+	Name   string   `json:"name"`
+	Locale string   `json:"locale"`
+	Gender Gender   `json:"gender"`
+	Styles []string `json:"styles"`
+}
+
+// HasStyle - whether the voice supports style.
+func (v Voice) HasStyle(style string) bool {
+	for _, s := range v.Styles {
+		if s == style {
+			return true
+		}
+	}
+	return false
+}
+
+// Voices - voices by locale, e.g. "en_US".
+type Voices map[string][]Voice
+
+// VoiceFilter - selects voices, see Voices.Filter.
+type VoiceFilter func(v Voice) bool
+
+// GetVoices - returns the voices available to Synthesize.
+//
+// https://wit.ai/docs/http/#get__voices_link
+func (c *Client) GetVoices() (Voices, error) {
+	resp, err := c.request(http.MethodGet, "/voices", "application/json", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Close()
+
+	var voices Voices
+	if err := json.NewDecoder(resp).Decode(&voices); err != nil {
+		return nil, err
+	}
+
+	for locale, list := range voices {
+		for i := range list {
+			if list[i].Locale == "" {
+				list[i].Locale = locale
+			}
+		}
+	}
+
+	return voices, nil
+}
+
+// All - returns all voices, sorted by locale and name.
+func (v Voices) All() []Voice {
+	var all []Voice
+	for _, list := range v {
+		all = append(all, list...)
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Locale != all[j].Locale {
+			return all[i].Locale < all[j].Locale
+		}
+		return all[i].Name < all[j].Name
+	})
+	return all
+}
+
+// Find - returns the voice with the given name.
+func (v Voices) Find(name string) (Voice, bool) {
+	for _, voice := range v.All() {
+		if voice.Name == name {
+			return voice, true
+		}
+	}
+	return Voice{}, false
+}
+
+// Filter - returns the voices matching all filters, sorted as All.
+func (v Voices) Filter(filters ...VoiceFilter) []Voice {
+	var voices []Voice
+	for _, voice := range v.All() {
+		if matchVoice(voice, filters) {
+			voices = append(voices, voice)
+		}
+	}
+	return voices
+}
+
+func matchVoice(v Voice, filters []VoiceFilter) bool {
+	for _, f := range filters {
+		if !f(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// ForLocale - matches voices of locale, e.g. "en_US" or "en-US". A locale
+// without region, e.g. "en", matches all regions.
+func ForLocale(locale string) VoiceFilter {
+	want, err := ParseLocaleTag(locale)
+	return func(v Voice) bool {
+		if err != nil {
+			return false
+		}
+		tag, err := ParseLocaleTag(v.Locale)
+		if err != nil || tag.Language != want.Language {
+			return false
+		}
+		return want.Region == "" || tag.Region == want.Region
+	}
+}
+
+// ForGender - matches voices of gender g.
+func ForGender(g Gender) VoiceFilter {
+	return func(v Voice) bool {
+		return v.Gender == g
+	}
+}
+
+// ForStyle - matches voices supporting style.
+func ForStyle(style string) VoiceFilter {
+	return func(v Voice) bool {
+		return v.HasStyle(style)
+	}
+}
+
+// ValidateRequest - checks the voice and style of req exist in the catalog.
+func (v Voices) ValidateRequest(req *SynthesizeRequest) error {
+	voice, ok := v.Find(req.Voice)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownVoice, req.Voice)
+	}
+	if req.Style != "" && !voice.HasStyle(req.Style) {
+		return fmt.Errorf("%w: voice %q has no style %q", ErrUnsupportedStyle, req.Voice, req.Style)
+	}
+	return nil
+}
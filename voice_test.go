@@ -0,0 +1,79 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved.
+
+package witai
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVoices(t *testing.T) Voices {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet || req.URL.Path != "/voices" {
+			t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+		}
+		res.Write([]byte(`{
+			"en_US": [
+				{"name": "Rebecca", "locale": "en_US", "gender": "female", "styles": ["default", "soft", "formal"]},
+				{"name": "Cooper", "locale": "en_US", "gender": "male", "styles": ["default", "formal"]}
+			],
+			"en_GB": [
+				{"name": "Charlie", "gender": "male", "styles": ["default"]}
+			],
+			"fr_FR": [
+				{"name": "Camille", "locale": "fr_FR", "gender": "female", "styles": ["default", "soft"]}
+			]
+		}`))
+	}))
+	defer testServer.Close()
+
+	c := NewClient(unitTestToken)
+	c.APIBase = testServer.URL
+
+	voices, err := c.GetVoices()
+	require.NoError(t, err)
+	return voices
+}
+
+func voiceNames(voices []Voice) []string {
+	names := []string{}
+	for _, v := range voices {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+func TestGetVoices(t *testing.T) {
+	voices := newTestVoices(t)
+	require.Len(t, voices, 3)
+	require.Equal(t, []string{"Charlie", "Cooper", "Rebecca", "Camille"}, voiceNames(voices.All()))
+
+	v, ok := voices.Find("Charlie")
+	require.True(t, ok)
+	require.Equal(t, Voice{Name: "Charlie", Locale: "en_GB", Gender: GenderMale, Styles: []string{"default"}}, v)
+
+	_, ok = voices.Find("Nobody")
+	require.False(t, ok)
+}
+
+func TestVoicesFilter(t *testing.T) {
+	voices := newTestVoices(t)
+
+	require.Equal(t, []string{"Cooper", "Rebecca"}, voiceNames(voices.Filter(ForLocale("en-us"))))
+	require.Equal(t, []string{"Charlie", "Cooper", "Rebecca"}, voiceNames(voices.Filter(ForLocale("en"))))
+	require.Equal(t, []string{"Rebecca", "Camille"}, voiceNames(voices.Filter(ForGender(GenderFemale))))
+	require.Equal(t, []string{"Rebecca"}, voiceNames(voices.Filter(ForLocale("en"), ForStyle("soft"))))
+	require.Empty(t, voices.Filter(ForLocale("not a locale")))
+}
+
+func TestVoicesValidateRequest(t *testing.T) {
+	voices := newTestVoices(t)
+
+	require.NoError(t, voices.ValidateRequest(&SynthesizeRequest{Text: "hi", Voice: "Rebecca", Style: "soft"}))
+	require.NoError(t, voices.ValidateRequest(&SynthesizeRequest{Text: "hi", Voice: "Charlie"}))
+	require.ErrorIs(t, voices.ValidateRequest(&SynthesizeRequest{Text: "hi", Voice: "Nobody"}), ErrUnknownVoice)
+	require.ErrorIs(t, voices.ValidateRequest(&SynthesizeRequest{Text: "hi", Voice: "Cooper", Style: "soft"}), ErrUnsupportedStyle)
+}
@@ -3,6 +3,7 @@
 package witai
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -23,6 +24,7 @@ type Client struct {
 	headerAuth   string
 	headerAccept string
 	httpClient   *http.Client
+	limiter      *rateLimiter
 }
 
 type errorResp struct {
@@ -40,6 +42,16 @@ func (c *Client) SetHTTPClient(httpClient *http.Client) {
 	c.httpClient = httpClient
 }
 
+// SetRateLimit limits the number of requests per second sent by the client,
+// allowing bursts of up to burst requests. A rate <= 0 removes the limit.
+func (c *Client) SetRateLimit(perSecond float64, burst int) {
+	if perSecond <= 0 {
+		c.limiter = nil
+		return
+	}
+	c.limiter = newRateLimiter(perSecond, burst)
+}
+
 func newClientWithVersion(token, version string) *Client {
 	headerAuth := fmt.Sprintf("Bearer %s", token)
 	headerAccept := fmt.Sprintf("application/vnd.wit.%s+json", version)
@@ -59,13 +71,28 @@ func newClientWithVersion(token, version string) *Client {
 }
 
 func (c *Client) request(method, url string, ct string, body io.Reader) (io.ReadCloser, error) {
-	req, err := http.NewRequest(method, c.APIBase+url, body)
+	return c.requestContext(context.Background(), method, url, ct, body)
+}
+
+func (c *Client) requestContext(ctx context.Context, method, url string, ct string, body io.Reader) (io.ReadCloser, error) {
+	return c.requestAccept(ctx, method, url, ct, c.headerAccept, body)
+}
+
+// requestAccept - same as requestContext, with the given Accept header.
+func (c *Client) requestAccept(ctx context.Context, method, url string, ct string, accept string, body io.Reader) (io.ReadCloser, error) {
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.APIBase+url, body)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Authorization", c.headerAuth)
-	req.Header.Set("Accept", c.headerAccept)
+	req.Header.Set("Accept", accept)
 	req.Header.Set("Content-Type", ct)
 
 	resp, err := c.httpClient.Do(req)
@@ -97,3 +124,22 @@ func (c *Client) request(method, url string, ct string, body io.Reader) (io.Read
 
 	return resp.Body, nil
 }
+
+// timeoutContext returns a context bound by the client timeout, for the
+// requests without context sent with streaming.
+func (c *Client) timeoutContext() (context.Context, context.CancelFunc) {
+	if c.httpClient.Timeout > 0 {
+		return context.WithTimeout(context.Background(), c.httpClient.Timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// streaming returns a copy of the client without timeout, for requests
+// lasting as long as their audio: they are only bound to their context.
+func (c *Client) streaming() *Client {
+	streaming := *c
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+	streaming.httpClient = &httpClient
+	return &streaming
+}